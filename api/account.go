@@ -1,19 +1,76 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/fx"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/pagination"
 	"github.com/forabbie/vank-app/token"
+	"github.com/forabbie/vank-app/util"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 )
 
+type accountResponse struct {
+	ID             int64        `json:"id"`
+	Owner          string       `json:"owner"`
+	Balance        money.Amount `json:"balance"`
+	Currency       string       `json:"currency"`
+	OverdraftLimit money.Amount `json:"overdraft_limit"`
+	Status         string       `json:"status"`
+	// FrozenReason and FreezeBlocksIncoming are only shown to the owner and bank staff
+	FrozenReason         string     `json:"frozen_reason,omitempty"`
+	FreezeBlocksIncoming bool       `json:"freeze_blocks_incoming,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	ClosedAt             *time.Time `json:"closed_at,omitempty"`
+}
+
+func (server *Server) newAccountResponse(ctx context.Context, account db.Account) (accountResponse, error) {
+	exponent, err := server.currencies.Exponent(ctx, account.Currency)
+	if err != nil {
+		return accountResponse{}, err
+	}
+
+	rsp := accountResponse{
+		ID:             account.ID,
+		Owner:          account.Owner,
+		Balance:        money.New(account.Balance, account.Currency, exponent),
+		Currency:       account.Currency,
+		OverdraftLimit: money.New(account.OverdraftLimit, account.Currency, exponent),
+		Status:         account.Status,
+		CreatedAt:      account.CreatedAt,
+	}
+	if account.ClosedAt.Valid {
+		rsp.ClosedAt = &account.ClosedAt.Time
+	}
+	return rsp, nil
+}
+
+// newAccountDetailsResponse also tells why the account is frozen, which
+// must not leak to other users, e.g. in the response of a transfer
+func (server *Server) newAccountDetailsResponse(ctx context.Context, account db.Account) (accountResponse, error) {
+	rsp, err := server.newAccountResponse(ctx, account)
+	if err != nil {
+		return rsp, err
+	}
+
+	if account.Status == db.AccountStatusFrozen {
+		rsp.FrozenReason = account.FrozenReason.String
+		rsp.FreezeBlocksIncoming = account.FreezeBlocksIncoming
+	}
+	return rsp, nil
+}
+
 type createAccountRequest struct {
 	// Owner    string `json:"owner" binding:"required"`
-	Currency string `json:"currency" binding:"required,currency"`
+	Currency string `json:"currency" binding:"required"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -23,6 +80,10 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
+	if !server.supportedCurrency(ctx, req.Currency) {
+		return
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
 	arg := db.CreateAccountParams{
@@ -46,7 +107,13 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	ctx.JSON(http.StatusOK, account)
+
+	rsp, err := server.newAccountResponse(ctx, account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type getAccountRequest struct {
@@ -72,18 +139,31 @@ func (server *Server) getAccount(ctx *gin.Context) {
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if account.Owner != authPayload.Username && !util.CanViewAnyAccount(authPayload.Role) {
 		err := errors.New("account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	rsp, err := server.newAccountDetailsResponse(ctx, account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type listAccountRequest struct {
-	Page  int32 `form:"page" binding:"required,min=1"`
-	Limit int32 `form:"limit" binding:"required,min=5,max=10"`
+	// Page selects a page by offset. It is kept for existing clients,
+	// new ones should follow next_cursor instead.
+	Page   int32  `form:"page" binding:"omitempty,min=1,excluded_with=Cursor"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type listAccountResponse struct {
+	Accounts   []accountResponse `json:"accounts"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
 func (server *Server) listAccount(ctx *gin.Context) {
@@ -93,18 +173,215 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	limit := pagination.PageSize(req.Limit)
+
+	if req.Page > 0 {
+		arg := db.ListAccountsParams{
+			Owner:  authPayload.Username,
+			Limit:  limit,
+			Offset: (req.Page - 1) * limit,
+		}
+
+		accounts, err := server.store.ListAccounts(ctx, arg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		rsp, err := server.newAccountResponses(ctx, accounts)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	scope := "accounts:" + authPayload.Username
+	after, err := server.cursors.Decode(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListAccountsAfterParams{
+		Owner:          authPayload.Username,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		PageSize:       limit + 1,
+	}
+
+	accounts, err := server.store.ListAccountsAfter(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	accounts, next := pagination.Trim(accounts, limit, func(account db.Account) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: account.CreatedAt, ID: account.ID}
+	})
+	rsp, err := server.newAccountResponses(ctx, accounts)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, listAccountResponse{
+		Accounts:   rsp,
+		NextCursor: server.cursors.Next(scope, next),
+	})
+}
+
+func (server *Server) newAccountResponses(ctx context.Context, accounts []db.Account) ([]accountResponse, error) {
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		var err error
+		rsp[i], err = server.newAccountResponse(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rsp, nil
+}
+
+type closeAccountRequest struct {
+	// SweepToAccountID receives the remaining balance, if there is any
+	SweepToAccountID int64 `json:"sweep_to_account_id" binding:"omitempty,min=1"`
+}
+
+type closeAccountResponse struct {
+	Account accountResponse     `json:"account"`
+	Sweep   *transferTxResponse `json:"sweep,omitempty"`
+}
+
+// closeAccount closes an account of the authenticated user for good
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// The body is optional when the balance is already zero
+	var req closeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.existingAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.CloseAccountTx(ctx, db.CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: req.SweepToAccountID,
+		// The owner can't hold two accounts in one currency, so the balance is usually converted
+		Convert: func(q db.Querier, amount int64, from string, to string) (int64, string, error) {
+			conversion, err := fx.Quote(ctx, q, server.currencies, amount, from, to)
+			return conversion.ToAmount, conversion.Rate, err
+		},
+		Reason:    "closed by the owner",
+		ChangedBy: authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrBalanceNotZero),
+			errors.Is(err, db.ErrInvalidSweepAccount),
+			errors.Is(err, db.ErrAccountFrozen),
+			errors.Is(err, db.ErrAccountClosed),
+			errors.Is(err, fx.ErrRateNotFound),
+			errors.Is(err, fx.ErrAmountTooSmall),
+			errors.Is(err, fx.ErrAmountTooLarge):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	var rsp closeAccountResponse
+	rsp.Account, err = server.newAccountResponse(ctx, result.Account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if result.Sweep != nil {
+		sweep, err := server.newTransferTxResponse(ctx, *result.Sweep)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		rsp.Sweep = &sweep
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateOverdraftLimitRequest struct {
+	// OverdraftLimit is a decimal string in the account currency, such as "500.00"
+	OverdraftLimit string `json:"overdraft_limit" binding:"required"`
+}
+
+// updateOverdraftLimit sets how far below zero the balance of an account may go
+func (server *Server) updateOverdraftLimit(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateOverdraftLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.existingAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	limit, err := server.currencies.Parse(ctx, req.OverdraftLimit, account.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if limit.Sign() < 0 || limit.Value > db.MaxTransferAmount {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("overdraft limit out of range")))
+		return
+	}
 
-	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.Limit,
-		Offset: (req.Page - 1) * req.Limit,
+	if account.Status == db.AccountStatusClosed {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(db.ErrAccountClosed))
+		return
 	}
 
-	accounts, err := server.store.ListAccounts(ctx, arg)
+	account, err = server.store.UpdateAccountOverdraftLimit(ctx, db.UpdateAccountOverdraftLimitParams{
+		ID:             account.ID,
+		OverdraftLimit: limit.Value,
+	})
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	rsp, err := server.newAccountDetailsResponse(ctx, account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
 }
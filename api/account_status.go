@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/token"
+	"github.com/gin-gonic/gin"
+)
+
+type accountStatusChangeResponse struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedBy  string    `json:"changed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newAccountStatusChangeResponse(change db.AccountStatusChange) accountStatusChangeResponse {
+	return accountStatusChangeResponse{
+		ID:         change.ID,
+		AccountID:  change.AccountID,
+		FromStatus: change.FromStatus,
+		ToStatus:   change.ToStatus,
+		Reason:     change.Reason,
+		ChangedBy:  change.ChangedBy,
+		CreatedAt:  change.CreatedAt,
+	}
+}
+
+type accountStatusResponse struct {
+	Account accountResponse             `json:"account"`
+	Change  accountStatusChangeResponse `json:"change"`
+}
+
+func (server *Server) newAccountStatusResponse(ctx context.Context, result db.AccountStatusTxResult) (accountStatusResponse, error) {
+	account, err := server.newAccountDetailsResponse(ctx, result.Account)
+	if err != nil {
+		return accountStatusResponse{}, err
+	}
+
+	return accountStatusResponse{
+		Account: account,
+		Change:  newAccountStatusChangeResponse(result.Change),
+	}, nil
+}
+
+type freezeAccountRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+	// BlockIncoming stops the account from receiving money too
+	BlockIncoming bool `json:"block_incoming"`
+}
+
+// freezeAccount stops an account from sending money until it is unfrozen
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req freezeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	result, err := server.store.FreezeAccountTx(ctx, db.FreezeAccountTxParams{
+		AccountID:     uri.ID,
+		Reason:        req.Reason,
+		BlockIncoming: req.BlockIncoming,
+		ChangedBy:     authPayload.Username,
+	})
+	if err != nil {
+		accountStatusError(ctx, err)
+		return
+	}
+
+	rsp, err := server.newAccountStatusResponse(ctx, result)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type unfreezeAccountRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// unfreezeAccount makes a frozen account active again
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req unfreezeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	result, err := server.store.UnfreezeAccountTx(ctx, db.UnfreezeAccountTxParams{
+		AccountID: uri.ID,
+		Reason:    req.Reason,
+		ChangedBy: authPayload.Username,
+	})
+	if err != nil {
+		accountStatusError(ctx, err)
+		return
+	}
+
+	rsp, err := server.newAccountStatusResponse(ctx, result)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func accountStatusError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrAccountNotFrozen):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+// listAccountStatusChanges returns the audit trail of an account, oldest change first
+func (server *Server) listAccountStatusChanges(ctx *gin.Context) {
+	var req getAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.existingAccount(ctx, req.ID); !valid {
+		return
+	}
+
+	changes, err := server.store.ListAccountStatusChanges(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]accountStatusChangeResponse, len(changes))
+	for i, change := range changes {
+		rsp[i] = newAccountStatusChangeResponse(change)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
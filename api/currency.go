@@ -0,0 +1,104 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list currencies"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+}
+
+func (server *Server) createCurrency(ctx *gin.Context) {
+	var req db.CreateCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	violations := validator.ValidateCreateCurrencyRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	isEnabled := true
+	if req.IsEnabled != nil {
+		isEnabled = *req.IsEnabled
+	}
+
+	currency, err := server.store.CreateCurrency(ctx, db.CreateCurrencyParams{
+		Code:      req.Code,
+		Exponent:  req.Exponent,
+		IsEnabled: isEnabled,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "currency already exists"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create currency"})
+		return
+	}
+
+	// Other servers pick it up once their cache expires
+	server.currencies.Invalidate()
+
+	ctx.JSON(http.StatusOK, currency)
+}
+
+func (server *Server) updateCurrency(ctx *gin.Context) {
+	var req db.UpdateCurrencyRequest
+
+	// Bind the code from the URL and the flag from JSON
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid currency code"})
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	violations := validator.ValidateUpdateCurrencyRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	currency, err := server.store.UpdateCurrencyEnabled(ctx, db.UpdateCurrencyEnabledParams{
+		Code:      req.Code,
+		IsEnabled: *req.IsEnabled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "currency not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update currency"})
+		return
+	}
+
+	// Other servers pick it up once their cache expires
+	server.currencies.Invalidate()
+
+	ctx.JSON(http.StatusOK, currency)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+type listEntriesRequest struct {
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type listEntriesResponse struct {
+	Entries    []entryResponse `json:"entries"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func (server *Server) listEntries(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scope := fmt.Sprintf("entries:%d", uri.ID)
+	after, err := server.cursors.Decode(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.readableAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	limit := pagination.PageSize(req.Limit)
+	arg := db.ListEntriesAfterParams{
+		AccountID:      account.ID,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		PageSize:       limit + 1,
+	}
+
+	entries, err := server.store.ListEntriesAfter(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	entries, next := pagination.Trim(entries, limit, func(entry db.Entry) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+	})
+
+	rsp := listEntriesResponse{
+		Entries:    make([]entryResponse, len(entries)),
+		NextCursor: server.cursors.Next(scope, next),
+	}
+	for i, entry := range entries {
+		rsp.Entries[i], err = server.newEntryResponse(ctx, entry, account.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
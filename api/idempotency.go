@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyParams builds the idempotency parameters of a request from its Idempotency-Key header.
+// It returns nil if the client did not send the header.
+func idempotencyParams(ctx *gin.Context, username string, fingerprint interface{}) (*db.IdempotencyParams, error) {
+	return idempotency.Params(ctx.GetHeader(idempotency.Header), username, fingerprint)
+}
+
+// replayResponse writes the response recorded for an idempotency key.
+// It returns false without writing anything if the key has not been used yet.
+func (server *Server) replayResponse(ctx *gin.Context, arg db.IdempotencyParams) bool {
+	result, found, err := idempotency.Replay(ctx, server.store, arg)
+	if err != nil {
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return true
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+	if !found {
+		return false
+	}
+
+	rsp, err := server.newTransferTxResponse(ctx, result)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+	ctx.JSON(http.StatusOK, rsp)
+	return true
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/forabbie/vank-app/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// allowLoginAttempt responds with 429 and returns false while the username or client IP is throttled
+func (server *Server) allowLoginAttempt(ctx *gin.Context, username string, clientIP string) bool {
+	wait, locked, err := server.loginThrottle.RetryAfter(ctx, username, clientIP)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return false
+	}
+	if wait > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(auth.LoginError(locked)))
+		return false
+	}
+	return true
+}
@@ -1,28 +1,86 @@
 package api
 
 import (
+	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/forabbie/vank-app/currency"
+	mockdb "github.com/forabbie/vank-app/database/mock"
 	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/token"
 	"github.com/forabbie/vank-app/util"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:     util.RandomString(32),
+		CursorSigningKey:      util.RandomString(32),
+		AccessTokenDuration:   time.Minute,
+		CurrencyCacheDuration: time.Minute,
 	}
 
-	server, err := NewServer(config, store)
+	var server *Server
+
+	// Unless a test stubs it first, users have never changed their password
+	// and still have the role their token was issued with
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			GetUserAuthorization(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			DoAndReturn(func(ctx context.Context, username string) (db.GetUserAuthorizationRow, error) {
+				return db.GetUserAuthorizationRow{Role: tokenRole(t, server.tokenMaker, ctx)}, nil
+			})
+
+		// and only the currencies seeded by the migrations exist
+		mockStore.EXPECT().
+			ListCurrencies(gomock.Any()).
+			AnyTimes().
+			Return(seededCurrencies(), nil)
+	}
+
+	server, err := NewServer(config, store, nil)
 	require.NoError(t, err)
 
 	return server
 }
 
+// tokenRole returns the role in the access token of the request being served
+func tokenRole(t *testing.T, tokenMaker token.Maker, ctx context.Context) string {
+	fields := strings.Fields(ctx.(*gin.Context).GetHeader(authorizationHeaderKey))
+	require.Len(t, fields, 2)
+
+	payload, err := tokenMaker.VerifyToken(fields[1])
+	require.NoError(t, err)
+	return payload.Role
+}
+
+// newResponseBuilder returns a server that only knows the seeded currencies,
+// to build the responses the handlers are expected to render
+func newResponseBuilder(t *testing.T) *Server {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return(seededCurrencies(), nil)
+
+	return &Server{currencies: currency.NewRegistry(store, time.Minute)}
+}
+
+func seededCurrencies() []db.Currency {
+	return []db.Currency{
+		{Code: util.CAD, Exponent: 2, IsEnabled: true},
+		{Code: util.EUR, Exponent: 2, IsEnabled: true},
+		{Code: util.USD, Exponent: 2, IsEnabled: true},
+	}
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/forabbie/vank-app/auth"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/token"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	errMfaAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	errMfaNotEnrolled    = errors.New("no two-factor secret has been enrolled")
+)
+
+type mfaChallengeResponse struct {
+	MfaRequired       bool      `json:"mfa_required"`
+	MfaToken          uuid.UUID `json:"mfa_token"`
+	MfaTokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+// createMfaChallenge answers a correct password of a user with two-factor authentication
+// with a short-lived token that is exchanged for the session at /users/login/mfa
+func (server *Server) createMfaChallenge(ctx *gin.Context, user db.User) {
+	challenge, err := server.store.CreateMfaChallenge(ctx, db.CreateMfaChallengeParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(server.config.MfaChallengeDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create mfa challenge"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mfaChallengeResponse{
+		MfaRequired:       true,
+		MfaToken:          challenge.ID,
+		MfaTokenExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+func (server *Server) verifyLoginMfa(ctx *gin.Context) {
+	var req db.VerifyLoginMfaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.FormatValidationErrors(err))
+		return
+	}
+
+	// Validate request fields
+	violations := validator.ValidateVerifyLoginMfaRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	challenge, err := server.store.GetMfaChallenge(ctx, uuid.MustParse(req.MfaToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(auth.ErrInvalidMfaToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+
+	if challenge.IsUsed || challenge.Attempts >= auth.MaxMfaChallengeAttempts || time.Now().After(challenge.ExpiresAt) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(auth.ErrInvalidMfaToken))
+		return
+	}
+
+	// Wrong codes count as failed logins, so guessing them is throttled like guessing passwords
+	clientIP := ctx.ClientIP()
+	if !server.allowLoginAttempt(ctx, challenge.Username, clientIP) {
+		return
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, challenge.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+
+	valid, err := auth.CheckMfaCode(ctx, server.store, server.config.MfaEncryptionKey, user, req.Code, true)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+	if !valid {
+		if _, err := server.store.FailMfaChallenge(ctx, challenge.ID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": "internal server error",
+			})
+			return
+		}
+		if err := server.loginThrottle.RecordFailure(ctx, user.Username, clientIP, true); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": "internal server error",
+			})
+			return
+		}
+		ctx.JSON(http.StatusUnauthorized, errorResponse(auth.ErrInvalidMfaCode))
+		return
+	}
+
+	// A challenge is exchanged for a session only once, even by concurrent requests
+	_, err = server.store.UseMfaChallenge(ctx, challenge.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(auth.ErrInvalidMfaToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+
+	server.createLoginSession(ctx, user)
+}
+
+type enrollTotpResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+}
+
+// enrollTotp generates a new TOTP secret for the authenticated user.
+// Two-factor authentication is only turned on once a code from it is confirmed.
+func (server *Server) enrollTotp(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if user.IsMfaEnabled {
+		ctx.JSON(http.StatusConflict, errorResponse(errMfaAlreadyEnabled))
+		return
+	}
+
+	secret, otpauthURL, err := util.NewTOTPKey(user.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	encryptedSecret, err := util.Encrypt(server.config.MfaEncryptionKey, secret)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.SetUserTotpSecret(ctx, db.SetUserTotpSecretParams{
+		TotpSecret: sql.NullString{
+			String: encryptedSecret,
+			Valid:  true,
+		},
+		Username: user.Username,
+	})
+	if err != nil {
+		// Enabled by a concurrent request
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(errMfaAlreadyEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTotpResponse{
+		Secret:     secret,
+		OtpauthURL: otpauthURL,
+	})
+}
+
+type confirmTotpResponse struct {
+	User          userResponse `json:"user"`
+	RecoveryCodes []string     `json:"recovery_codes"`
+}
+
+// confirmTotp turns on two-factor authentication once the user proves their authenticator
+// app produces codes for the enrolled secret. The recovery codes are only ever shown here.
+func (server *Server) confirmTotp(ctx *gin.Context) {
+	var req db.ConfirmTotpRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.FormatValidationErrors(err))
+		return
+	}
+
+	// Validate request fields
+	violations := validator.ValidateConfirmTotpRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if user.IsMfaEnabled {
+		ctx.JSON(http.StatusConflict, errorResponse(errMfaAlreadyEnabled))
+		return
+	}
+
+	if !user.TotpSecret.Valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errMfaNotEnrolled))
+		return
+	}
+
+	secret, err := util.Decrypt(server.config.MfaEncryptionKey, user.TotpSecret.String)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	step, ok := util.ValidateTOTP(secret, req.Code, time.Now())
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse(auth.ErrInvalidMfaCode))
+		return
+	}
+
+	recoveryCodes, err := util.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hashedRecoveryCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedRecoveryCodes[i] = util.HashRecoveryCode(code)
+	}
+
+	result, err := server.store.EnableMfaTx(ctx, db.EnableMfaTxParams{
+		EnableUserMfaParams: db.EnableUserMfaParams{
+			Username: user.Username,
+			// The confirmation code can't be replayed to log in
+			TotpLastUsedStep: step,
+		},
+		HashedRecoveryCodes: hashedRecoveryCodes,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(errMfaNotEnrolled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTotpResponse{
+		User:          newUserResponse(result.User),
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// validTransferMfa requires a fresh TOTP code for transfers worth at least the configured amount
+// of the reference currency from users with two-factor authentication.
+// It responds and returns false when the check fails.
+func (server *Server) validTransferMfa(ctx *gin.Context, username string, amount money.Amount, code string) bool {
+	user, required, err := auth.TransferNeedsMfa(ctx, server.store, server.currencies, server.config, username, amount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if !required {
+		return true
+	}
+
+	if code == "" {
+		ctx.JSON(http.StatusForbidden, errorResponse(auth.ErrMfaRequired))
+		return false
+	}
+
+	clientIP := ctx.ClientIP()
+	if !server.allowLoginAttempt(ctx, username, clientIP) {
+		return false
+	}
+
+	valid, err := auth.CheckMfaCode(ctx, server.store, server.config.MfaEncryptionKey, user, code, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if !valid {
+		if err := server.loginThrottle.RecordFailure(ctx, username, clientIP, true); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusForbidden, errorResponse(auth.ErrInvalidMfaCode))
+		return false
+	}
+	return true
+}
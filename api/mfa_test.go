@@ -0,0 +1,663 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/auth"
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/token"
+	"github.com/forabbie/vank-app/util"
+	mockwk "github.com/forabbie/vank-app/worker/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+)
+
+// randomMfaUser returns a user with two-factor authentication enabled,
+// along with their TOTP secret in the clear
+func randomMfaUser(t *testing.T, encryptionKey string) (user db.User, password string, secret string) {
+	user, password = randomUser(t)
+
+	secret, _, err := util.NewTOTPKey(user.Username)
+	require.NoError(t, err)
+
+	encryptedSecret, err := util.Encrypt(encryptionKey, secret)
+	require.NoError(t, err)
+
+	user.TotpSecret = sql.NullString{String: encryptedSecret, Valid: true}
+	user.IsMfaEnabled = true
+	return
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+// wrongTOTPCode returns a 6 digit code that differs from the current one
+func wrongTOTPCode(t *testing.T, secret string) string {
+	code := currentTOTPCode(t, secret)
+	var n int
+	_, err := fmt.Sscanf(code, "%d", &n)
+	require.NoError(t, err)
+	return fmt.Sprintf("%06d", (n+500000)%1000000)
+}
+
+func TestVerifyLoginMfaAPI(t *testing.T) {
+	encryptionKey := util.RandomString(32)
+	user, _, secret := randomMfaUser(t, encryptionKey)
+	recoveryCode := "0123456789"
+
+	challenge := db.MfaChallenge{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	expiredChallenge := challenge
+	expiredChallenge.ExpiresAt = time.Now().Add(-time.Second)
+
+	exhaustedChallenge := challenge
+	exhaustedChallenge.Attempts = auth.MaxMfaChallengeAttempts
+
+	testCases := []struct {
+		name          string
+		body          func() gin.H
+		buildStubs    func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.UpdateUserTotpStepParams) (int64, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, time.Now().Unix()/30, arg.TotpLastUsedStep, 1)
+						return 1, nil
+					})
+				store.EXPECT().
+					UseMfaRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UseMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.Equal(t, user.Username, rsp.User.Username)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": recoveryCode}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				arg := db.UseMfaRecoveryCodeParams{
+					Username:   user.Username,
+					HashedCode: util.HashRecoveryCode(recoveryCode),
+				}
+				store.EXPECT().
+					UseMfaRecoveryCode(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.MfaRecoveryCode{Username: user.Username, HashedCode: arg.HashedCode}, nil)
+				store.EXPECT().
+					UseMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": wrongTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseMfaRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaRecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					FailMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RecordLoginFailureTxParams) (db.RecordLoginFailureTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotNil(t, arg.AfterLock)
+						return db.RecordLoginFailureTxResult{Failures: 1}, nil
+					})
+				store.EXPECT().
+					UseMfaChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), auth.ErrInvalidMfaCode.Error())
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				// The step of the code was used already
+				store.EXPECT().
+					UpdateUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					FailMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecordLoginFailureTxResult{Failures: 1}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeNotFound",
+			body: func() gin.H {
+				return gin.H{"mfa_token": uuid.New(), "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), auth.ErrInvalidMfaToken.Error())
+			},
+		},
+		{
+			name: "ExpiredChallenge",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(expiredChallenge, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), auth.ErrInvalidMfaToken.Error())
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(exhaustedChallenge, nil)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeUsedConcurrently",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					UseMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(db.MfaChallenge{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Throttled",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				stubLoginFailures(store, 3, 0)
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "InvalidMfaToken",
+			body: func() gin.H {
+				return gin.H{"mfa_token": "invalid", "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": challenge.ID, "code": "12ab"}
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetMfaChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			taskDistributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, taskDistributor)
+
+			server := newTestServer(t, store)
+			server.taskDistributor = taskDistributor
+			server.config.MfaEncryptionKey = encryptionKey
+			server.config.LoginMaxFailures = 3
+			server.config.LoginMaxIPFailures = 10
+			server.config.LoginBaseDelay = time.Second
+			server.config.LoginLockoutDuration = 15 * time.Minute
+			server.loginThrottle = auth.NewLoginThrottle(server.config, store, taskDistributor)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			url := "/api/v1/users/login/mfa"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestEnrollTotpAPI(t *testing.T) {
+	encryptionKey := util.RandomString(32)
+	user, _ := randomUser(t)
+	mfaUser, _, _ := randomMfaUser(t, encryptionKey)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, storedSecret *string)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, storedSecret *string) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp enrollTotpResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.Secret)
+				require.Contains(t, rsp.OtpauthURL, "otpauth://totp/")
+
+				// The secret is only stored encrypted
+				require.NotEqual(t, rsp.Secret, *storedSecret)
+				secret, err := util.Decrypt(encryptionKey, *storedSecret)
+				require.NoError(t, err)
+				require.Equal(t, rsp.Secret, secret)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, mfaUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(mfaUser.Username)).
+					Times(1).
+					Return(mfaUser, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, storedSecret *string) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Empty(t, *storedSecret)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, storedSecret *string) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var storedSecret string
+			store.EXPECT().
+				SetUserTotpSecret(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(_ any, arg db.SetUserTotpSecretParams) (db.User, error) {
+					storedSecret = arg.TotpSecret.String
+					return user, nil
+				})
+
+			server := newTestServer(t, store)
+			server.config.MfaEncryptionKey = encryptionKey
+			recorder := httptest.NewRecorder()
+
+			url := "/api/v1/users/mfa/totp"
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, &storedSecret)
+		})
+	}
+}
+
+func TestConfirmTotpAPI(t *testing.T) {
+	encryptionKey := util.RandomString(32)
+	mfaUser, _, secret := randomMfaUser(t, encryptionKey)
+
+	// Enrolled, but not confirmed yet
+	user := mfaUser
+	user.IsMfaEnabled = false
+
+	notEnrolledUser, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          func() gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body: func() gin.H {
+				return gin.H{"code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					EnableMfaTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.EnableMfaTxParams) (db.EnableMfaTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, time.Now().Unix()/30, arg.TotpLastUsedStep, 1)
+						require.Len(t, arg.HashedRecoveryCodes, recoveryCodeCount)
+						return db.EnableMfaTxResult{User: mfaUser}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp confirmTotpResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.True(t, rsp.User.IsMfaEnabled)
+				require.Len(t, rsp.RecoveryCodes, recoveryCodeCount)
+			},
+		},
+		{
+			name:     "WrongCode",
+			username: user.Username,
+			body: func() gin.H {
+				return gin.H{"code": wrongTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					EnableMfaTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), auth.ErrInvalidMfaCode.Error())
+			},
+		},
+		{
+			name:     "NotEnrolled",
+			username: notEnrolledUser.Username,
+			body: func() gin.H {
+				return gin.H{"code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(notEnrolledUser.Username)).
+					Times(1).
+					Return(notEnrolledUser, nil)
+				store.EXPECT().
+					EnableMfaTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errMfaNotEnrolled.Error())
+			},
+		},
+		{
+			name:     "AlreadyEnabled",
+			username: mfaUser.Username,
+			body: func() gin.H {
+				return gin.H{"code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(mfaUser.Username)).
+					Times(1).
+					Return(mfaUser, nil)
+				store.EXPECT().
+					EnableMfaTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCode",
+			username: user.Username,
+			body: func() gin.H {
+				return gin.H{"code": "0123456789"}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.MfaEncryptionKey = encryptionKey
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			url := "/api/v1/users/mfa/totp/verify"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
+	db "github.com/forabbie/vank-app/database/sqlc"
 	"github.com/forabbie/vank-app/token"
 	"github.com/gin-gonic/gin"
 )
@@ -16,8 +19,11 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-// AuthMiddleware creates a gin middleware for authorization
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// AuthMiddleware creates a gin middleware for authorization.
+// Tokens issued before the user last changed their password are rejected.
+// Handlers see the current role of the user, not the one the token was issued with,
+// and when allowed roles are given, only users with one of them are let through.
+func authMiddleware(store db.Store, tokenMaker token.Maker, allowedRoles ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
@@ -48,7 +54,40 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
+		user, err := store.GetUserAuthorization(ctx, payload.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("user not found")))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if payload.IssuedAt.Before(user.PasswordChangedAt) {
+			err := errors.New("token was issued before the password was changed")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		// The role may have changed since the token was issued,
+		// so a demoted user loses their privileges right away
+		payload = withRole(payload, user.Role)
+
+		if len(allowedRoles) > 0 && !slices.Contains(allowedRoles, payload.Role) {
+			err := fmt.Errorf("role %s is not allowed to access this resource", payload.Role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
 }
+
+// withRole returns a copy of the payload with the given role
+func withRole(payload *token.Payload, role string) *token.Payload {
+	current := *payload
+	current.Role = role
+	return &current
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/forabbie/vank-app/auth"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type forgotPasswordResponse struct {
+	IsSent bool `json:"is_sent"`
+}
+
+// forgotPassword emails a reset code to the user with the given email.
+// It answers the same whether the email belongs to a user or not, so it does not reveal which emails are registered,
+// and limits the requests per email and per client IP.
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req db.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.FormatValidationErrors(err))
+		return
+	}
+
+	// Validate request fields
+	violations := validator.ValidateForgotPasswordRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	// Requests are throttled and recorded before anything depends on the email,
+	// so unknown emails take the same path and time as registered ones
+	clientIP := ctx.ClientIP()
+	wait, err := server.resetThrottle.RetryAfter(ctx, req.Email, clientIP)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+	if wait > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(auth.ErrTooManyPasswordResets))
+		return
+	}
+
+	if err := server.resetThrottle.RecordRequest(ctx, req.Email, clientIP); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to send reset password email",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, forgotPasswordResponse{
+		IsSent: true,
+	})
+}
+
+type resetPasswordResponse struct {
+	IsReset bool `json:"is_reset"`
+}
+
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req db.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.FormatValidationErrors(err))
+		return
+	}
+
+	// Validate request fields
+	violations := validator.ValidateResetPasswordRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		return
+	}
+
+	_, err = server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		ResetId:        req.ResetId,
+		SecretCode:     req.SecretCode,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid or expired reset code",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to reset password",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resetPasswordResponse{
+		IsReset: true,
+	})
+}
@@ -0,0 +1,346 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/auth"
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
+	mockwk "github.com/forabbie/vank-app/worker/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForgotPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	noRequests := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetEmailPasswordResetRequests(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.GetEmailPasswordResetRequestsRow{}, nil)
+		store.EXPECT().
+			GetClientIPPasswordResetRequests(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.GetClientIPPasswordResetRequestsRow{}, nil)
+	}
+
+	// requestReset expects the request to be recorded and the email to be queued, whatever the email
+	requestReset := func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, email string) {
+		store.EXPECT().
+			RequestPasswordResetTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(ctx context.Context, arg db.RequestPasswordResetTxParams) (db.RequestPasswordResetTxResult, error) {
+				require.Equal(t, strings.ToLower(email), arg.Email)
+				return db.RequestPasswordResetTxResult{}, arg.AfterCreate(store)
+			})
+		payload := &worker.PayloadSendResetPasswordEmail{Email: email}
+		taskDistributor.EXPECT().
+			DistributeTaskSendResetPasswordEmail(gomock.Any(), gomock.Any(), gomock.Eq(payload)).
+			Times(1).
+			Return(nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				noRequests(store)
+				requestReset(store, taskDistributor, user.Email)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownEmail",
+			body: gin.H{
+				"email": "unknown_" + user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				// The handler never looks the user up, the task processor does
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+				noRequests(store)
+				requestReset(store, taskDistributor, "unknown_"+user.Email)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// Same answer as for a registered email
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp forgotPasswordResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.True(t, rsp.IsSent)
+			},
+		},
+		{
+			name: "TooManyRequestsForEmail",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetEmailPasswordResetRequests(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.GetEmailPasswordResetRequestsParams) (db.GetEmailPasswordResetRequestsRow, error) {
+						require.Equal(t, strings.ToLower(user.Email), arg.Email)
+						require.WithinDuration(t, time.Now().Add(-time.Hour), arg.Since, time.Second)
+						return db.GetEmailPasswordResetRequestsRow{Requests: 3, LastRequestedAt: time.Now()}, nil
+					})
+				store.EXPECT().
+					GetClientIPPasswordResetRequests(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetClientIPPasswordResetRequestsRow{Requests: 3, LastRequestedAt: time.Now()}, nil)
+				store.EXPECT().
+					RequestPasswordResetTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "3600", recorder.Header().Get("Retry-After"))
+				require.Contains(t, recorder.Body.String(), auth.ErrTooManyPasswordResets.Error())
+			},
+		},
+		{
+			name: "TooManyRequestsFromIP",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetEmailPasswordResetRequests(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetEmailPasswordResetRequestsRow{}, nil)
+				store.EXPECT().
+					GetClientIPPasswordResetRequests(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetClientIPPasswordResetRequestsRow{Requests: 20, LastRequestedAt: time.Now().Add(-30 * time.Minute)}, nil)
+				store.EXPECT().
+					RequestPasswordResetTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "1800", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				noRequests(store)
+				store.EXPECT().
+					RequestPasswordResetTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RequestPasswordResetTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: gin.H{
+				"email": "invalid-email",
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetEmailPasswordResetRequests(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RequestPasswordResetTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			taskDistributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, taskDistributor)
+
+			server := newTestServer(t, store)
+			server.taskDistributor = taskDistributor
+			server.config.ResetPasswordMaxRequests = 3
+			server.config.ResetPasswordMaxIPRequests = 20
+			server.config.ResetPasswordWindow = time.Hour
+			server.resetThrottle = auth.NewPasswordResetThrottle(server.config, store, taskDistributor)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/api/v1/users/password/forgot"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	resetID := util.RandomInt(1, 1000)
+	secretCode := util.RandomString(32)
+	newPassword := util.RandomString(8)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"reset_id":    resetID,
+				"secret_code": secretCode,
+				"password":    newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+						require.Equal(t, resetID, arg.ResetId)
+						require.Equal(t, secretCode, arg.SecretCode)
+						require.NoError(t, util.CheckPassword(newPassword, arg.HashedPassword))
+						return db.ResetPasswordTxResult{User: user, BlockedSessions: 2}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp resetPasswordResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.True(t, rsp.IsReset)
+			},
+		},
+		{
+			name: "InvalidOrExpiredCode",
+			body: gin.H{
+				"reset_id":    resetID,
+				"secret_code": secretCode,
+				"password":    newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"reset_id":    resetID,
+				"secret_code": secretCode,
+				"password":    newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "ShortPassword",
+			body: gin.H{
+				"reset_id":    resetID,
+				"secret_code": secretCode,
+				"password":    "abc",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidSecretCode",
+			body: gin.H{
+				"reset_id":    resetID,
+				"secret_code": "short",
+				"password":    newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/api/v1/users/password/reset"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,38 +1,57 @@
 package api
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/forabbie/vank-app/auth"
+	"github.com/forabbie/vank-app/currency"
 	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pagination"
 	"github.com/forabbie/vank-app/token"
 	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator/v10"
 )
 
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config          util.Config
+	store           db.Store
+	tokenMaker      token.Maker
+	taskDistributor worker.TaskDistributor
+	currencies      *currency.Registry
+	cursors         *pagination.Signer
+	loginThrottle   *auth.LoginThrottle
+	resetThrottle   *auth.PasswordResetThrottle
+	router          *gin.Engine
 }
 
 // NewServer creates a new HTTP server and set up routing
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	cursors, err := pagination.NewSigner(config.CursorSigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cursor signer: %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:          config,
+		store:           store,
+		tokenMaker:      tokenMaker,
+		taskDistributor: taskDistributor,
+		currencies:      currency.NewRegistry(store, config.CurrencyCacheDuration),
+		cursors:         cursors,
+		loginThrottle:   auth.NewLoginThrottle(config, store, taskDistributor),
+		resetThrottle:   auth.NewPasswordResetThrottle(config, store, taskDistributor),
 	}
 
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+	// Amounts are formatted with the exponents of the currencies, so they must be known first
+	if err := server.currencies.Load(context.Background()); err != nil {
+		return nil, fmt.Errorf("cannot load currencies: %w", err)
 	}
 
 	server.setupRouter()
@@ -49,18 +68,48 @@ func (server *Server) setupRouter() {
 
 	apiV1.POST("/users", server.createUser)
 	apiV1.POST("/users/login", server.loginUser)
+	apiV1.POST("/users/login/mfa", server.verifyLoginMfa)
+	apiV1.POST("/users/logout", server.logoutUser)
 	apiV1.POST("/tokens/renew_access", server.renewAccessToken)
+	apiV1.GET("/verify_email", server.verifyEmail)
+	apiV1.GET("/users/unlock", server.unlockAccount)
+	apiV1.POST("/users/password/forgot", server.forgotPassword)
+	apiV1.POST("/users/password/reset", server.resetPassword)
 
-	authRoutes := apiV1.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := apiV1.Group("/").Use(authMiddleware(server.store, server.tokenMaker))
 
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
 	authRoutes.GET("/accounts", server.listAccount)
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)
+	authRoutes.GET("/accounts/:id/entries", server.listEntries)
+	authRoutes.GET("/accounts/:id/transfers", server.searchAccountTransfers)
+	authRoutes.GET("/accounts/:id/statement", server.getStatement)
+	authRoutes.POST("/accounts/:id/statement/email", server.emailStatement)
 
 	authRoutes.POST("/transfers", server.createTransfer)
 	authRoutes.GET("/transfers", server.listTransfers)
 
 	authRoutes.PATCH("/users/:id", server.updateUser)
+	authRoutes.POST("/users/mfa/totp", server.enrollTotp)
+	authRoutes.POST("/users/mfa/totp/verify", server.confirmTotp)
+
+	authRoutes.GET("/sessions", server.listSessions)
+	authRoutes.DELETE("/sessions/:id", server.revokeSession)
+	authRoutes.DELETE("/sessions", server.revokeAllSessions)
+
+	adminRoutes := apiV1.Group("/").Use(authMiddleware(server.store, server.tokenMaker, util.AdminRole))
+
+	adminRoutes.PATCH("/users/:id/role", server.updateUserRole)
+
+	adminRoutes.PATCH("/accounts/:id/overdraft_limit", server.updateOverdraftLimit)
+	adminRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	adminRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	adminRoutes.GET("/accounts/:id/status_changes", server.listAccountStatusChanges)
+
+	adminRoutes.GET("/currencies", server.listCurrencies)
+	adminRoutes.POST("/currencies", server.createCurrency)
+	adminRoutes.PATCH("/currencies/:code", server.updateCurrency)
 
 	server.router = router
 }
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/statement"
+	"github.com/forabbie/vank-app/token"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type statementRequest struct {
+	// From and To are dates in UTC, both included in the statement
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To   time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	// Format is json by default, or a file format
+	Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+}
+
+func (req statementRequest) period() statement.Period {
+	return statement.Period{
+		From: req.From,
+		To:   req.To,
+	}
+}
+
+type statementEntryResponse struct {
+	ID                    int64             `json:"id"`
+	Amount                money.Amount      `json:"amount"`
+	Balance               money.Amount      `json:"balance"`
+	CounterpartyAccountID int64             `json:"counterparty_account_id,omitempty"`
+	Transfer              *transferResponse `json:"transfer,omitempty"`
+	CreatedAt             time.Time         `json:"created_at"`
+}
+
+type statementResponse struct {
+	Account        accountResponse          `json:"account"`
+	From           string                   `json:"from"`
+	To             string                   `json:"to"`
+	OpeningBalance money.Amount             `json:"opening_balance"`
+	ClosingBalance money.Amount             `json:"closing_balance"`
+	Entries        []statementEntryResponse `json:"entries"`
+}
+
+func (server *Server) newStatementResponse(ctx context.Context, period statement.Period, result db.StatementTxResult) (statementResponse, error) {
+	currency := result.Account.Currency
+	exponent, err := server.currencies.Exponent(ctx, currency)
+	if err != nil {
+		return statementResponse{}, err
+	}
+
+	account, err := server.newAccountResponse(ctx, result.Account)
+	if err != nil {
+		return statementResponse{}, err
+	}
+
+	rsp := statementResponse{
+		Account:        account,
+		From:           period.From.Format(statement.DateFormat),
+		To:             period.To.Format(statement.DateFormat),
+		OpeningBalance: money.New(result.OpeningBalance, currency, exponent),
+		ClosingBalance: money.New(result.ClosingBalance, currency, exponent),
+		Entries:        make([]statementEntryResponse, len(result.Lines)),
+	}
+
+	for i, line := range result.Lines {
+		entry := statementEntryResponse{
+			ID:                    line.Entry.ID,
+			Amount:                money.New(line.Entry.Amount, currency, exponent),
+			Balance:               money.New(line.Balance, currency, exponent),
+			CounterpartyAccountID: line.CounterpartyAccountID,
+			CreatedAt:             line.Entry.CreatedAt,
+		}
+		if line.Transfer != nil {
+			transfer, err := server.newTransferResponse(ctx, line.Transfer.Transfer, line.Transfer.FromCurrency, line.Transfer.ToCurrency)
+			if err != nil {
+				return statementResponse{}, err
+			}
+			entry.Transfer = &transfer
+		}
+		rsp.Entries[i] = entry
+	}
+
+	return rsp, nil
+}
+
+func (server *Server) getStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req statementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	period := req.period()
+	if err := period.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.readableAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.StatementTx(ctx, db.StatementTxParams{
+		AccountID: account.ID,
+		From:      period.From,
+		To:        period.End(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.Format == "" || req.Format == "json" {
+		rsp, err := server.newStatementResponse(ctx, period, result)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	// Render fully before writing, so that a failure can still be reported as an error
+	file, err := statement.New(ctx, server.currencies, result, period)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	var buf bytes.Buffer
+	if err := file.Render(&buf, req.Format); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Filename(req.Format)))
+	ctx.Data(http.StatusOK, statement.ContentType(req.Format), buf.Bytes())
+}
+
+type emailStatementRequest struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To     time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	Format string    `form:"format" binding:"required,oneof=csv pdf"`
+}
+
+type emailStatementResponse struct {
+	IsSent bool `json:"is_sent"`
+}
+
+// emailStatement emails the statement file to the caller as an attachment.
+// The file is rendered and sent by the task processor.
+func (server *Server) emailStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req emailStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	period := statement.Period{From: req.From, To: req.To}
+	if err := period.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.readableAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	payload := &worker.PayloadSendStatementEmail{
+		Username:  authPayload.Username,
+		AccountID: account.ID,
+		Period:    period,
+		Format:    req.Format,
+	}
+	err := server.taskDistributor.DistributeTaskSendStatementEmail(ctx, server.store, payload)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, emailStatementResponse{
+		IsSent: true,
+	})
+}
+
+// readableAccount fetches an account and checks that the caller can read its history
+func (server *Server) readableAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username && !util.CanViewAnyAccount(authPayload.Role) {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
@@ -1,21 +1,114 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/fx"
+	"github.com/forabbie/vank-app/idempotency"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/pagination"
 	"github.com/forabbie/vank-app/token"
+	"github.com/forabbie/vank-app/util"
 	"github.com/gin-gonic/gin"
 )
 
+type transferResponse struct {
+	ID            int64        `json:"id"`
+	FromAccountID int64        `json:"from_account_id"`
+	ToAccountID   int64        `json:"to_account_id"`
+	Amount        money.Amount `json:"amount"`
+	ToAmount      money.Amount `json:"to_amount"`
+	FxRate        string       `json:"fx_rate"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+func (server *Server) newTransferResponse(ctx context.Context, transfer db.Transfer, fromCurrency string, toCurrency string) (transferResponse, error) {
+	amount, err := server.currencies.Amount(ctx, transfer.Amount, fromCurrency)
+	if err != nil {
+		return transferResponse{}, err
+	}
+	toAmount, err := server.currencies.Amount(ctx, transfer.ToAmount, toCurrency)
+	if err != nil {
+		return transferResponse{}, err
+	}
+
+	return transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        amount,
+		ToAmount:      toAmount,
+		FxRate:        transfer.FxRate,
+		CreatedAt:     transfer.CreatedAt,
+	}, nil
+}
+
+type entryResponse struct {
+	ID        int64        `json:"id"`
+	AccountID int64        `json:"account_id"`
+	Amount    money.Amount `json:"amount"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+func (server *Server) newEntryResponse(ctx context.Context, entry db.Entry, currency string) (entryResponse, error) {
+	amount, err := server.currencies.Amount(ctx, entry.Amount, currency)
+	if err != nil {
+		return entryResponse{}, err
+	}
+
+	return entryResponse{
+		ID:        entry.ID,
+		AccountID: entry.AccountID,
+		Amount:    amount,
+		CreatedAt: entry.CreatedAt,
+	}, nil
+}
+
+type transferTxResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"from_account"`
+	ToAccount   accountResponse  `json:"to_account"`
+	FromEntry   entryResponse    `json:"from_entry"`
+	ToEntry     entryResponse    `json:"to_entry"`
+}
+
+func (server *Server) newTransferTxResponse(ctx context.Context, result db.TransferTxResult) (transferTxResponse, error) {
+	fromCurrency := result.FromAccount.Currency
+	toCurrency := result.ToAccount.Currency
+
+	var rsp transferTxResponse
+	var err error
+	if rsp.Transfer, err = server.newTransferResponse(ctx, result.Transfer, fromCurrency, toCurrency); err != nil {
+		return rsp, err
+	}
+	if rsp.FromAccount, err = server.newAccountResponse(ctx, result.FromAccount); err != nil {
+		return rsp, err
+	}
+	if rsp.ToAccount, err = server.newAccountResponse(ctx, result.ToAccount); err != nil {
+		return rsp, err
+	}
+	if rsp.FromEntry, err = server.newEntryResponse(ctx, result.FromEntry, fromCurrency); err != nil {
+		return rsp, err
+	}
+	if rsp.ToEntry, err = server.newEntryResponse(ctx, result.ToEntry, toCurrency); err != nil {
+		return rsp, err
+	}
+	return rsp, nil
+}
+
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,min=1"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" binding:"required,min=1"`
+	// Amount is a decimal string in the currency, such as "12.50"
+	Amount   string `json:"amount" binding:"required"`
+	Currency string `json:"currency" binding:"required"`
+	TotpCode string `json:"totp_code,omitempty" binding:"omitempty,len=6,numeric"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -25,38 +118,124 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	if !server.supportedCurrency(ctx, req.Currency) {
+		return
+	}
+
+	amount, err := server.currencies.Parse(ctx, req.Amount, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if amount.Sign() <= 0 || amount.Value > db.MaxTransferAmount {
+		ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrInvalidTransferAmount))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	fingerprint := idempotency.Transfer{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        amount.String(),
+		Currency:      req.Currency,
+	}
+	idempotencyArg, err := idempotencyParams(ctx, authPayload.Username, fingerprint)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// A retried request gets the response of the original one instead of moving money again
+	if idempotencyArg != nil && server.replayResponse(ctx, *idempotencyArg) {
+		return
+	}
+
 	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if fromAccount.Owner != authPayload.Username {
 		err := errors.New("from account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+
+	// The destination account may hold another currency, the amount is then converted
+	toAccount, valid := server.existingAccount(ctx, req.ToAccountID)
 	if !valid {
 		return
 	}
 
+	if !server.validTransferMfa(ctx, authPayload.Username, amount, req.TotpCode) {
+		return
+	}
+
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		Amount:        amount.Value,
+		Idempotency:   idempotencyArg,
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	var result db.TransferTxResult
+	if toAccount.Currency == fromAccount.Currency {
+		result, err = server.store.TransferTx(ctx, arg)
+	} else {
+		var conversion fx.Conversion
+		conversion, err = fx.Quote(ctx, server.store, server.currencies, amount.Value, fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			if errors.Is(err, fx.ErrRateNotFound) || errors.Is(err, fx.ErrAmountTooSmall) || errors.Is(err, fx.ErrAmountTooLarge) {
+				ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		result, err = server.store.FxTransferTx(ctx, db.FxTransferTxParams{
+			TransferTxParams: arg,
+			ToAmount:         conversion.ToAmount,
+			FxRate:           conversion.Rate,
+		})
+	}
 	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrInvalidTransferAmount) || errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		// A concurrent request with the same key committed first
+		if errors.Is(err, db.ErrIdempotencyKeyExists) && server.replayResponse(ctx, *idempotencyArg) {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	rsp, err := server.newTransferTxResponse(ctx, result)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, valid := server.existingAccount(ctx, accountID)
+	if !valid {
+		return account, false
+	}
+
+	if account.Currency != currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s (expected: %s)", accountID, account.Currency, currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return account, false
+	}
+	return account, true
+}
+
+func (server *Server) existingAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -67,20 +246,22 @@ func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency s
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return account, false
 	}
-
-	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s (expected: %s)", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return account, false
-	}
 	return account, true
 }
 
 type listTransfersRequest struct {
 	FromAccountID int64 `form:"from_account_id" binding:"required"`
 	ToAccountID   int64 `form:"to_account_id" binding:"required"`
-	Page          int32 `form:"page" binding:"required,min=1"`
-	Limit         int32 `form:"limit" binding:"required,min=5,max=10"`
+	// Page selects a page by offset. It is kept for existing clients,
+	// new ones should follow next_cursor instead.
+	Page   int32  `form:"page" binding:"omitempty,min=1,excluded_with=Cursor"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type listTransfersResponse struct {
+	Transfers  []transferResponse `json:"transfers"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 func (server *Server) listTransfers(ctx *gin.Context) {
@@ -90,33 +271,124 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 		return
 	}
 
+	// Rows match either account, and the viewer filter only lets through the
+	// ones involving an account of the caller, so one readable account is enough
+	if !server.readableEitherAccount(ctx, req.FromAccountID, req.ToAccountID) {
+		return
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	viewer := transferViewer(authPayload)
+	limit := pagination.PageSize(req.Limit)
 
-	// Fetch account details to check ownership
-	account, err := server.store.GetAccount(ctx, req.FromAccountID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if req.Page > 0 {
+		arg := db.ListTransfersParams{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   req.ToAccountID,
+			Viewer:        viewer,
+			Limit:         limit,
+			Offset:        (req.Page - 1) * limit,
+		}
+
+		transfers, err := server.store.ListTransfers(ctx, arg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		rsp := make([]transferResponse, len(transfers))
+		for i, transfer := range transfers {
+			rsp[i], err = server.newTransferResponse(ctx, transfer.Transfer, transfer.FromCurrency, transfer.ToCurrency)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusOK, rsp)
 		return
 	}
 
-	// Ensure the user can only view their own transactions
-	if authPayload.Username != account.Owner {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("unauthorized account access")))
+	scope := fmt.Sprintf("transfers:%d:%d", req.FromAccountID, req.ToAccountID)
+	after, err := server.cursors.Decode(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	arg := db.ListTransfersParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Limit:         req.Limit,
-		Offset:        (req.Page - 1) * req.Limit,
+	arg := db.ListTransfersAfterParams{
+		FromAccountID:  req.FromAccountID,
+		ToAccountID:    req.ToAccountID,
+		Viewer:         viewer,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		PageSize:       limit + 1,
 	}
 
-	transfers, err := server.store.ListTransfers(ctx, arg)
+	transfers, err := server.store.ListTransfersAfter(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transfers)
+	transfers, next := pagination.Trim(transfers, limit, func(transfer db.ListTransfersAfterRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: transfer.Transfer.CreatedAt, ID: transfer.Transfer.ID}
+	})
+
+	rsp := listTransfersResponse{
+		Transfers:  make([]transferResponse, len(transfers)),
+		NextCursor: server.cursors.Next(scope, next),
+	}
+	for i, transfer := range transfers {
+		rsp.Transfers[i], err = server.newTransferResponse(ctx, transfer.Transfer, transfer.FromCurrency, transfer.ToCurrency)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// transferViewer returns the owner every listed transfer must involve,
+// or NULL for roles that can read any account
+// readableEitherAccount checks that both accounts exist and that the caller
+// owns one of them, or can view any account
+func (server *Server) readableEitherAccount(ctx *gin.Context, fromAccountID, toAccountID int64) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	readable := util.CanViewAnyAccount(authPayload.Role)
+
+	accountIDs := []int64{fromAccountID}
+	if toAccountID != fromAccountID {
+		accountIDs = append(accountIDs, toAccountID)
+	}
+
+	for _, accountID := range accountIDs {
+		account, err := server.store.GetAccount(ctx, accountID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return false
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+
+		if account.Owner == authPayload.Username {
+			readable = true
+		}
+	}
+
+	if !readable {
+		err := errors.New("neither account belongs to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+func transferViewer(payload *token.Payload) sql.NullString {
+	if util.CanViewAnyAccount(payload.Role) {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: payload.Username, Valid: true}
 }
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// Directions of the transfers of an account
+const (
+	directionIncoming = "incoming"
+	directionOutgoing = "outgoing"
+	directionBoth     = "both"
+)
+
+type searchTransfersRequest struct {
+	Direction string `form:"direction" binding:"omitempty,oneof=incoming outgoing both"`
+	// From and To are dates in UTC, both included
+	From time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To   time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	// MinAmount and MaxAmount are decimal amounts in the currency of the account, both included
+	MinAmount      string `form:"min_amount"`
+	MaxAmount      string `form:"max_amount"`
+	CounterpartyID int64  `form:"counterparty_id" binding:"omitempty,min=1"`
+	Sort           string `form:"sort" binding:"omitempty,oneof=asc desc"`
+	Limit          int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor         string `form:"cursor"`
+}
+
+// searchAccountTransfers lists the transfers in and out of an account, newest first unless asked otherwise
+func (server *Server) searchAccountTransfers(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req searchTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("to must not be before from")))
+		return
+	}
+
+	account, valid := server.readableAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	exponent, err := server.currencies.Exponent(ctx, account.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg, err := newSearchTransfersParams(account, exponent, req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// The cursor is bound to the filters, so that it can't be carried over to another search
+	scope := searchTransfersScope(arg)
+	after, err := server.cursors.Decode(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Cursor != "" {
+		arg.AfterCreatedAt = sql.NullTime{Time: after.CreatedAt, Valid: true}
+		arg.AfterID = sql.NullInt64{Int64: after.ID, Valid: true}
+	}
+
+	limit := pagination.PageSize(req.Limit)
+	arg.PageSize = limit + 1
+
+	transfers, err := server.store.SearchTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	transfers, next := pagination.Trim(transfers, limit, func(transfer db.SearchAccountTransfersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: transfer.Transfer.CreatedAt, ID: transfer.Transfer.ID}
+	})
+
+	rsp := listTransfersResponse{
+		Transfers:  make([]transferResponse, len(transfers)),
+		NextCursor: server.cursors.Next(scope, next),
+	}
+	for i, transfer := range transfers {
+		rsp.Transfers[i], err = server.newTransferResponse(ctx, transfer.Transfer, transfer.FromCurrency, transfer.ToCurrency)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// newSearchTransfersParams turns the filters of a request into query parameters, without paging
+func newSearchTransfersParams(account db.Account, exponent int32, req searchTransfersRequest) (db.SearchTransfersParams, error) {
+	arg := db.SearchTransfersParams{
+		SearchAccountTransfersParams: db.SearchAccountTransfersParams{
+			AccountID: account.ID,
+			Outgoing:  req.Direction != directionIncoming,
+			Incoming:  req.Direction != directionOutgoing,
+		},
+		NewestFirst: req.Sort != "asc",
+	}
+
+	if req.CounterpartyID != 0 {
+		arg.CounterpartyID = sql.NullInt64{Int64: req.CounterpartyID, Valid: true}
+	}
+	if !req.From.IsZero() {
+		arg.FromTime = sql.NullTime{Time: req.From, Valid: true}
+	}
+	if !req.To.IsZero() {
+		// the last day is included
+		arg.ToTime = sql.NullTime{Time: req.To.AddDate(0, 0, 1), Valid: true}
+	}
+
+	var err error
+	arg.MinAmount, err = parseAmountFilter(req.MinAmount, account.Currency, exponent)
+	if err != nil {
+		return arg, fmt.Errorf("min_amount: %w", err)
+	}
+	arg.MaxAmount, err = parseAmountFilter(req.MaxAmount, account.Currency, exponent)
+	if err != nil {
+		return arg, fmt.Errorf("max_amount: %w", err)
+	}
+	if arg.MinAmount.Valid && arg.MaxAmount.Valid && arg.MaxAmount.Int64 < arg.MinAmount.Int64 {
+		return arg, errors.New("max_amount must not be less than min_amount")
+	}
+
+	return arg, nil
+}
+
+// parseAmountFilter parses an optional amount bound, which may not be negative
+func parseAmountFilter(s string, currency string, exponent int32) (sql.NullInt64, error) {
+	if s == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	amount, err := money.Parse(s, currency, exponent)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	if amount.Sign() < 0 {
+		return sql.NullInt64{}, errors.New("must not be negative")
+	}
+	return sql.NullInt64{Int64: amount.Value, Valid: true}, nil
+}
+
+func searchTransfersScope(arg db.SearchTransfersParams) string {
+	return fmt.Sprintf("transfers:%d:%t:%t:%s:%s:%s:%s:%s:%t",
+		arg.AccountID,
+		arg.Outgoing,
+		arg.Incoming,
+		formatNullInt64(arg.CounterpartyID),
+		formatNullTime(arg.FromTime),
+		formatNullTime(arg.ToTime),
+		formatNullInt64(arg.MinAmount),
+		formatNullInt64(arg.MaxAmount),
+		arg.NewestFirst,
+	)
+}
+
+func formatNullInt64(value sql.NullInt64) string {
+	if !value.Valid {
+		return ""
+	}
+	return fmt.Sprint(value.Int64)
+}
+
+func formatNullTime(value sql.NullTime) string {
+	if !value.Valid {
+		return ""
+	}
+	return value.Time.Format(time.RFC3339)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -10,8 +11,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/forabbie/vank-app/auth"
 	mockdb "github.com/forabbie/vank-app/database/mock"
 	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/idempotency"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/pagination"
 	"github.com/forabbie/vank-app/token"
 	"github.com/forabbie/vank-app/util"
 	"github.com/gin-gonic/gin"
@@ -34,6 +39,26 @@ func TestTransferAPI(t *testing.T) {
 	account2.Currency = util.USD
 	account3.Currency = util.EUR
 
+	idempotencyKey := util.RandomString(16)
+	idempotencyArg, err := idempotency.Params(idempotencyKey, user1.Username, idempotency.Transfer{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        decimalUSD(amount),
+		Currency:      util.USD,
+	})
+	require.NoError(t, err)
+	requestHash := idempotencyArg.RequestHash
+
+	storedResult := db.TransferTxResult{
+		Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount, ToAmount: amount, FxRate: "1"},
+		FromAccount: account1,
+		ToAccount:   account2,
+	}
+	storedResponse, err := json.Marshal(storedResult)
+	require.NoError(t, err)
+	replayedResponse, err := json.Marshal(expectedTransferTxResponse(t, storedResult))
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name          string
 		body          gin.H
@@ -46,11 +71,11 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
@@ -61,10 +86,26 @@ func TestTransferAPI(t *testing.T) {
 					ToAccountID:   account2.ID,
 					Amount:        amount,
 				}
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{
+					Transfer:    db.Transfer{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount, ToAmount: amount, FxRate: "1"},
+					FromAccount: account1,
+					ToAccount:   account2,
+					FromEntry:   db.Entry{AccountID: account1.ID, Amount: -amount},
+					ToEntry:     db.Entry{AccountID: account2.ID, Amount: amount},
+				}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// amounts are decimal strings in the currency of their account
+				var rsp struct {
+					Transfer  map[string]json.RawMessage `json:"transfer"`
+					FromEntry map[string]json.RawMessage `json:"from_entry"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.JSONEq(t, `{"value":"0.10","currency":"USD"}`, string(rsp.Transfer["amount"]))
+				require.JSONEq(t, `{"value":"0.10","currency":"USD"}`, string(rsp.Transfer["to_amount"]))
+				require.JSONEq(t, `{"value":"-0.10","currency":"USD"}`, string(rsp.FromEntry["amount"]))
 			},
 		},
 		{
@@ -72,11 +113,11 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
@@ -92,7 +133,7 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -110,11 +151,11 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
@@ -130,11 +171,11 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
@@ -150,11 +191,11 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account3.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
@@ -166,23 +207,62 @@ func TestTransferAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "ToAccountCurrencyMismatch",
+			name: "CrossCurrency",
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account3.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().
+					GetLatestFxRate(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FxRate{Base: util.USD, Quote: util.EUR, Rate: "0.92"}, nil)
+
+				arg := db.FxTransferTxParams{
+					TransferTxParams: db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account3.ID,
+						Amount:        amount,
+					},
+					ToAmount: 9,
+					FxRate:   "0.92",
+				}
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{FromAccount: account1, ToAccount: account3}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoFxRate",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          decimalUSD(amount),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().
+					GetLatestFxRate(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FxRate{}, sql.ErrNoRows)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 			},
 		},
 		{
@@ -190,11 +270,11 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        "XYZ",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -209,11 +289,11 @@ func TestTransferAPI(t *testing.T) {
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          -amount,
+				"amount":          decimalUSD(-amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -224,7 +304,26 @@ func TestTransferAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "GetAccountError",
+			name: "TooManyDecimals",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          "0.105",
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AmountNotString",
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
@@ -232,7 +331,26 @@ func TestTransferAPI(t *testing.T) {
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "GetAccountError",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          decimalUSD(amount),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
@@ -242,16 +360,192 @@ func TestTransferAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          decimalUSD(amount),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "AmountTooLarge",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          "92233720368547758.07",
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ToAccountClosed",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          decimalUSD(amount),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrAccountClosed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotentFirstRequest",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          decimalUSD(amount),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotency.Header, idempotencyKey)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Idempotency:   idempotencyArg,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{FromAccount: account1, ToAccount: account2}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotentReplay",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          decimalUSD(amount),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotency.Header, idempotencyKey)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetIdempotencyKeyParams{
+					Username:       user1.Username,
+					IdempotencyKey: idempotencyKey,
+				}
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.IdempotencyKey{
+					Username:       user1.Username,
+					IdempotencyKey: idempotencyKey,
+					RequestHash:    requestHash,
+					Response:       storedResponse,
+				}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, string(replayedResponse), recorder.Body.String())
+			},
+		},
+		{
+			name: "IdempotencyKeyReused",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          decimalUSD(amount * 2),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotency.Header, idempotencyKey)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{
+					Username:       user1.Username,
+					IdempotencyKey: idempotencyKey,
+					RequestHash:    requestHash,
+					Response:       storedResponse,
+				}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotencyKeyConcurrentRequest",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          decimalUSD(amount),
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotency.Header, idempotencyKey)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows),
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{
+						Username:       user1.Username,
+						IdempotencyKey: idempotencyKey,
+						RequestHash:    requestHash,
+						Response:       storedResponse,
+					}, nil),
+				)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrIdempotencyKeyExists)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, string(replayedResponse), recorder.Body.String())
+			},
+		},
 		{
 			name: "TransferTxError",
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          amount,
+				"amount":          decimalUSD(amount),
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
@@ -296,15 +590,26 @@ func TestListTransfersAPI(t *testing.T) {
 	user, _ := randomUser(t)
 
 	n := 5
-	transfers := make([]db.Transfer, n)
+	transfers := make([]db.ListTransfersRow, n)
 	for i := 0; i < n; i++ {
-		transfers[i] = randomTransfer(user.ID)
+		transfers[i] = db.ListTransfersRow{
+			Transfer:     randomTransfer(user.ID),
+			FromCurrency: util.USD,
+			ToCurrency:   util.EUR,
+		}
 	}
 
+	stranger := randomAccount(util.RandomOwner())
+	stranger.ID = user.ID + 1
+	otherAccount := randomAccount(user.Username)
+	otherAccount.ID = user.ID + 2
+
 	// Use exported field names
 	type Query struct {
-		Limit int
-		Page  int
+		FromAccountID int64
+		ToAccountID   int64
+		Limit         int
+		Page          int
 	}
 
 	testCases := []struct {
@@ -321,7 +626,7 @@ func TestListTransfersAPI(t *testing.T) {
 				Limit: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -331,6 +636,7 @@ func TestListTransfersAPI(t *testing.T) {
 				arg := db.ListTransfersParams{
 					FromAccountID: user.ID,
 					ToAccountID:   user.ID,
+					Viewer:        sql.NullString{String: user.Username, Valid: true},
 					Limit:         5,
 					Offset:        0,
 				}
@@ -346,14 +652,20 @@ func TestListTransfersAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "NoAuthorization",
+			name: "UnauthorizedUser",
 			query: Query{
 				Page:  1,
 				Limit: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Account{ID: user.ID, Owner: user.Username}, nil)
+
 				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -361,13 +673,13 @@ func TestListTransfersAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "InternalError",
+			name: "Banker",
 			query: Query{
 				Page:  1,
 				Limit: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "support_user", util.BankerRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -375,22 +687,239 @@ func TestListTransfersAPI(t *testing.T) {
 					Times(1).
 					Return(db.Account{ID: user.ID, Owner: user.Username}, nil)
 
+				// support staff may list transfers between any accounts
+				arg := db.ListTransfersParams{
+					FromAccountID: user.ID,
+					ToAccountID:   user.ID,
+					Limit:         5,
+					Offset:        0,
+				}
 				store.EXPECT().
-					ListTransfers(gomock.Any(), gomock.Any()).
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return([]db.Transfer{}, sql.ErrConnDone)
+					Return(transfers, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTransfers(t, recorder.Body, transfers)
 			},
 		},
-	}
+		{
+			name: "TransfersToStranger",
+			query: Query{
+				FromAccountID: user.ID,
+				ToAccountID:   stranger.ID,
+				Page:          1,
+				Limit:         n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Account{ID: user.ID, Owner: user.Username}, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(stranger.ID)).
+					Times(1).
+					Return(stranger, nil)
 
-	for i := range testCases {
-		tc := testCases[i]
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+				// what the stranger received from others is left out by the viewer filter
+				arg := db.ListTransfersParams{
+					FromAccountID: user.ID,
+					ToAccountID:   stranger.ID,
+					Viewer:        sql.NullString{String: user.Username, Valid: true},
+					Limit:         5,
+					Offset:        0,
+				}
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTransfers(t, recorder.Body, transfers)
+			},
+		},
+		{
+			name: "TransfersFromStranger",
+			query: Query{
+				FromAccountID: stranger.ID,
+				ToAccountID:   user.ID,
+				Page:          1,
+				Limit:         n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(stranger.ID)).
+					Times(1).
+					Return(stranger, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Account{ID: user.ID, Owner: user.Username}, nil)
+
+				arg := db.ListTransfersParams{
+					FromAccountID: stranger.ID,
+					ToAccountID:   user.ID,
+					Viewer:        sql.NullString{String: user.Username, Valid: true},
+					Limit:         5,
+					Offset:        0,
+				}
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTransfers(t, recorder.Body, transfers)
+			},
+		},
+		{
+			name: "TransfersBetweenStrangers",
+			query: Query{
+				FromAccountID: stranger.ID,
+				ToAccountID:   stranger.ID + 10,
+				Page:          1,
+				Limit:         n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(stranger.ID)).
+					Times(1).
+					Return(stranger, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(stranger.ID+10)).
+					Times(1).
+					Return(db.Account{ID: stranger.ID + 10, Owner: util.RandomOwner()}, nil)
+
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TwoOwnAccounts",
+			query: Query{
+				FromAccountID: user.ID,
+				ToAccountID:   otherAccount.ID,
+				Page:          1,
+				Limit:         n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Account{ID: user.ID, Owner: user.Username}, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+					Times(1).
+					Return(otherAccount, nil)
+
+				// the query itself only returns rows involving an account of the user
+				arg := db.ListTransfersParams{
+					FromAccountID: user.ID,
+					ToAccountID:   otherAccount.ID,
+					Viewer:        sql.NullString{String: user.Username, Valid: true},
+					Limit:         5,
+					Offset:        0,
+				}
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTransfers(t, recorder.Body, transfers)
+			},
+		},
+		{
+			name: "ToAccountNotFound",
+			query: Query{
+				FromAccountID: user.ID,
+				ToAccountID:   stranger.ID,
+				Page:          1,
+				Limit:         n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Account{ID: user.ID, Owner: user.Username}, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(stranger.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			query: Query{
+				Page:  1,
+				Limit: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: Query{
+				Page:  1,
+				Limit: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Account{ID: user.ID, Owner: user.Username}, nil)
+
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListTransfersRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
@@ -404,8 +933,13 @@ func TestListTransfersAPI(t *testing.T) {
 
 			// Add query parameters to request URL
 			q := request.URL.Query()
-			q.Add("from_account_id", fmt.Sprintf("%d", user.ID))
-			q.Add("to_account_id", fmt.Sprintf("%d", user.ID))
+			// Unless a case names other accounts, both sides are the user's account
+			fromAccountID, toAccountID := tc.query.FromAccountID, tc.query.ToAccountID
+			if fromAccountID == 0 {
+				fromAccountID, toAccountID = user.ID, user.ID
+			}
+			q.Add("from_account_id", fmt.Sprintf("%d", fromAccountID))
+			q.Add("to_account_id", fmt.Sprintf("%d", toAccountID))
 			q.Add("limit", fmt.Sprintf("%d", tc.query.Limit))
 			q.Add("page", fmt.Sprintf("%d", tc.query.Page))
 			request.URL.RawQuery = q.Encode()
@@ -417,19 +951,347 @@ func TestListTransfersAPI(t *testing.T) {
 	}
 }
 
+func TestListTransfersCursorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := db.Account{ID: user.ID, Owner: user.Username}
+
+	n := 3
+	transfers := make([]db.ListTransfersAfterRow, n)
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		transfer := randomTransfer(account.ID)
+		transfer.ID = int64(i + 1)
+		transfer.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+		transfers[i] = db.ListTransfersAfterRow{
+			Transfer:     transfer,
+			FromCurrency: util.USD,
+			ToCurrency:   util.EUR,
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).AnyTimes().Return(account, nil)
+
+	listTransfers := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		url := fmt.Sprintf("/api/v1/transfers?from_account_id=%d&to_account_id=%d&%s", account.ID, account.ID, query)
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	store.EXPECT().
+		ListTransfersAfter(gomock.Any(), gomock.Eq(db.ListTransfersAfterParams{
+			FromAccountID: account.ID,
+			ToAccountID:   account.ID,
+			Viewer:        sql.NullString{String: user.Username, Valid: true},
+			PageSize:      3,
+		})).
+		Times(1).
+		Return(transfers, nil)
+
+	recorder := listTransfers("limit=2")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var firstPage listTransfersResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &firstPage)
+	require.NoError(t, err)
+	require.Len(t, firstPage.Transfers, 2)
+	require.Equal(t, transfers[1].Transfer.ID, firstPage.Transfers[1].ID)
+	require.NotEmpty(t, firstPage.NextCursor)
+
+	store.EXPECT().
+		ListTransfersAfter(gomock.Any(), gomock.Eq(db.ListTransfersAfterParams{
+			FromAccountID:  account.ID,
+			ToAccountID:    account.ID,
+			Viewer:         sql.NullString{String: user.Username, Valid: true},
+			AfterCreatedAt: transfers[1].Transfer.CreatedAt,
+			AfterID:        transfers[1].Transfer.ID,
+			PageSize:       3,
+		})).
+		Times(1).
+		Return(transfers[2:], nil)
+
+	recorder = listTransfers("limit=2&cursor=" + firstPage.NextCursor)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var lastPage listTransfersResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &lastPage)
+	require.NoError(t, err)
+	require.Len(t, lastPage.Transfers, 1)
+	require.Empty(t, lastPage.NextCursor)
+
+	// a cursor is only valid for the accounts it was issued for
+	store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(0)
+
+	otherCursor := server.cursors.Encode(fmt.Sprintf("transfers:%d:%d", account.ID+1, account.ID+1), pagination.Cursor{ID: 1})
+	recorder = listTransfers("cursor=" + otherCursor)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = listTransfers("limit=101")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
 func randomTransfer(id int64) db.Transfer {
 	return db.Transfer{
 		ID:            util.RandomInt(1, 1000),
 		FromAccountID: id,
 		ToAccountID:   id,
 		Amount:        util.RandomMoney(),
+		ToAmount:      util.RandomMoney(),
+		FxRate:        "0.92",
 	}
 }
 
-func requireBodyMatchTransfers(t *testing.T, body *bytes.Buffer, expectedTransfers []db.Transfer) {
-	var gotTransfers []db.Transfer
+// decimalUSD formats an amount of cents the way clients send it
+func decimalUSD(value int64) string {
+	return money.New(value, util.USD, 2).String()
+}
+
+func expectedTransferResponse(t *testing.T, transfer db.Transfer, fromCurrency string, toCurrency string) transferResponse {
+	rsp, err := newResponseBuilder(t).newTransferResponse(context.Background(), transfer, fromCurrency, toCurrency)
+	require.NoError(t, err)
+	return rsp
+}
+
+func expectedEntryResponse(t *testing.T, entry db.Entry, currency string) entryResponse {
+	rsp, err := newResponseBuilder(t).newEntryResponse(context.Background(), entry, currency)
+	require.NoError(t, err)
+	return rsp
+}
+
+func expectedTransferTxResponse(t *testing.T, result db.TransferTxResult) transferTxResponse {
+	rsp, err := newResponseBuilder(t).newTransferTxResponse(context.Background(), result)
+	require.NoError(t, err)
+	return rsp
+}
+
+func requireBodyMatchTransfers(t *testing.T, body *bytes.Buffer, transfers []db.ListTransfersRow) {
+	var gotTransfers []transferResponse
 	err := json.NewDecoder(body).Decode(&gotTransfers)
 	require.NoError(t, err)
 
+	expectedTransfers := make([]transferResponse, len(transfers))
+	for i, transfer := range transfers {
+		expectedTransfers[i] = expectedTransferResponse(t, transfer.Transfer, transfer.FromCurrency, transfer.ToCurrency)
+	}
 	require.Equal(t, expectedTransfers, gotTransfers)
 }
+
+func TestTransferMfaAPI(t *testing.T) {
+	threshold := int64(1000)
+	encryptionKey := util.RandomString(32)
+
+	user, _, secret := randomMfaUser(t, encryptionKey)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	noMfaUser := user
+	noMfaUser.IsMfaEnabled = false
+
+	testCases := []struct {
+		name          string
+		body          func() gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "BelowThreshold",
+			body: func() gin.H {
+				return gin.H{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          decimalUSD(threshold - 1),
+					"currency":        util.USD,
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{FromAccount: account1, ToAccount: account2}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ValidCode",
+			body: func() gin.H {
+				return gin.H{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          decimalUSD(threshold),
+					"currency":        util.USD,
+					"totp_code":       currentTOTPCode(t, secret),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().UpdateUserTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{FromAccount: account1, ToAccount: account2}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MfaNotEnabled",
+			body: func() gin.H {
+				return gin.H{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          decimalUSD(threshold),
+					"currency":        util.USD,
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(noMfaUser, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{FromAccount: account1, ToAccount: account2}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingCode",
+			body: func() gin.H {
+				return gin.H{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          decimalUSD(threshold),
+					"currency":        util.USD,
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), auth.ErrMfaRequired.Error())
+			},
+		},
+		{
+			name: "WrongCode",
+			body: func() gin.H {
+				return gin.H{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          decimalUSD(threshold),
+					"currency":        util.USD,
+					"totp_code":       wrongTOTPCode(t, secret),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				stubLoginFailures(store, 0, 0)
+				// Recovery codes are not accepted for transfers
+				store.EXPECT().UseMfaRecoveryCode(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RecordLoginFailureTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RecordLoginFailureTxResult{Failures: 1}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), auth.ErrInvalidMfaCode.Error())
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: func() gin.H {
+				return gin.H{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          decimalUSD(threshold),
+					"currency":        util.USD,
+					"totp_code":       currentTOTPCode(t, secret),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().UpdateUserTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().RecordLoginFailureTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RecordLoginFailureTxResult{Failures: 1}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func() gin.H {
+				return gin.H{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          decimalUSD(threshold),
+					"currency":        util.USD,
+					"totp_code":       "12ab56",
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.MfaEncryptionKey = encryptionKey
+			server.config.MfaTransferThreshold = threshold
+			server.config.MfaTransferThresholdCurrency = util.USD
+			server.config.LoginMaxFailures = 3
+			server.config.LoginMaxIPFailures = 10
+			server.config.LoginBaseDelay = time.Second
+			server.config.LoginLockoutDuration = 15 * time.Minute
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			url := "/api/v1/transfers"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
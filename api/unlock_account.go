@@ -0,0 +1,54 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type unlockAccountResponse struct {
+	IsUnlocked bool `json:"is_unlocked"`
+}
+
+func (server *Server) unlockAccount(ctx *gin.Context) {
+	var req db.UnlockAccountRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.FormatValidationErrors(err))
+		return
+	}
+
+	// Validate request fields
+	violations := validator.ValidateUnlockAccountRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	_, err := server.store.UnlockAccountTx(ctx, db.UnlockAccountTxParams{
+		UnlockId:   req.UnlockId,
+		SecretCode: req.SecretCode,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid or expired unlock link",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to unlock account",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, unlockAccountResponse{
+		IsUnlocked: true,
+	})
+}
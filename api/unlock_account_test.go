@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlockAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	unlockID := util.RandomInt(1, 1000)
+	secretCode := util.RandomString(32)
+
+	testCases := []struct {
+		name          string
+		unlockID      int64
+		secretCode    string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			unlockID:   unlockID,
+			secretCode: secretCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UnlockAccountTxParams{
+					UnlockId:   unlockID,
+					SecretCode: secretCode,
+				}
+				store.EXPECT().
+					UnlockAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.UnlockAccountTxResult{
+						UnlockAccount: db.UnlockAccount{ID: unlockID, Username: user.Username, IsUsed: true},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp unlockAccountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.True(t, rsp.IsUnlocked)
+			},
+		},
+		{
+			name:       "InvalidUnlockID",
+			unlockID:   -1,
+			secretCode: secretCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UnlockAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "TooShortSecretCode",
+			unlockID:   unlockID,
+			secretCode: util.RandomString(10),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UnlockAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "UsedOrExpiredLink",
+			unlockID:   unlockID,
+			secretCode: secretCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UnlockAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UnlockAccountTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "InternalError",
+			unlockID:   unlockID,
+			secretCode: secretCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UnlockAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UnlockAccountTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/users/unlock?unlock_id=%d&secret_code=%s", tc.unlockID, tc.secretCode)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/forabbie/vank-app/auth"
 	db "github.com/forabbie/vank-app/database/sqlc"
 	"github.com/forabbie/vank-app/token"
 	"github.com/forabbie/vank-app/util"
 	"github.com/forabbie/vank-app/validator"
+	"github.com/forabbie/vank-app/worker"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -19,19 +21,21 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	IsMfaEnabled      bool      `json:"is_mfa_enabled"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
-// type TaskProcessor struct {
-// 	mailer mail.EmailSender
-// }
-
 func newUserResponse(user db.User) userResponse {
 	return userResponse{
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		IsMfaEnabled:      user.IsMfaEnabled,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -61,14 +65,22 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.CreateUserParams{
-		Username:       req.Username,
-		HashedPassword: hashedPassword,
-		FullName:       req.FullName,
-		Email:          req.Email,
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.Username,
+			HashedPassword: hashedPassword,
+			FullName:       req.FullName,
+			Email:          req.Email,
+		},
+		AfterCreate: func(q db.Querier, user db.User) error {
+			// The email is sent by the task processor once the user has been committed
+			return server.taskDistributor.DistributeTaskSendVerifyEmail(ctx, q, &worker.PayloadSendVerifyEmail{
+				Username: user.Username,
+			})
+		},
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	result, err := server.store.CreateUserTx(ctx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -86,7 +98,7 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	rsp := newUserResponse(user)
+	rsp := newUserResponse(result.User)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
@@ -117,36 +129,61 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	clientIP := ctx.ClientIP()
+	if !server.allowLoginAttempt(ctx, req.Username, clientIP) {
+		return
+	}
+
 	user, err := server.store.GetUserByUsername(ctx, req.Username)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid username",
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+	userExists := err == nil
+
+	// Unknown usernames and wrong passwords get the same answer, after the same amount of work
+	hashedPassword := util.DummyHashedPassword
+	if userExists {
+		hashedPassword = user.HashedPassword
+	}
+	if err := util.CheckPassword(req.Password, hashedPassword); err != nil || !userExists {
+		if err := server.loginThrottle.RecordFailure(ctx, req.Username, clientIP, userExists); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": "internal server error",
 			})
 			return
 		}
+		ctx.JSON(http.StatusUnauthorized, errorResponse(auth.ErrInvalidCredentials))
+		return
+	}
+
+	if err := server.store.DeleteLoginFailures(ctx, user.Username); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": "internal server error",
 		})
 		return
 	}
 
-	// Verify password
-	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"error": "invalid password",
-		})
+	// With two-factor authentication the password only earns a challenge, exchanged for tokens at /users/login/mfa
+	if user.IsMfaEnabled {
+		server.createMfaChallenge(ctx, user)
 		return
 	}
 
-	// Generate tokens
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	server.createLoginSession(ctx, user)
+}
+
+// createLoginSession issues the access and refresh tokens of a successful login
+func (server *Server) createLoginSession(ctx *gin.Context, user db.User) {
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.AccessTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create access token"})
 		return
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.RefreshTokenDuration)
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.RefreshTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create refresh token"})
 		return
@@ -161,6 +198,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ClientIp:     ctx.ClientIP(),
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		FamilyID:     refreshPayload.ID,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
@@ -229,15 +267,24 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		email = sql.NullString{String: *req.Email, Valid: true}
 	}
 
-	arg := db.UpdateUserParams{
-		ID:             req.ID,
-		HashedPassword: hashedPassword,
-		FullName:       fullName,
-		Email:          email,
+	// Changing the password logs the user out of every other session
+	keepSessionID := uuid.NullUUID{}
+	if req.KeepSessionID != nil {
+		keepSessionID = uuid.NullUUID{UUID: uuid.MustParse(*req.KeepSessionID), Valid: true}
+	}
+
+	arg := db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			ID:             req.ID,
+			HashedPassword: hashedPassword,
+			FullName:       fullName,
+			Email:          email,
+		},
+		KeepSessionID: keepSessionID,
 	}
 
 	// Perform the update
-	updatedUser, err := server.store.UpdateUser(ctx, arg)
+	result, err := server.store.UpdateUserTx(ctx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -253,6 +300,46 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		return
 	}
 
-	rsp := newUserResponse(updatedUser)
+	rsp := newUserResponse(result.User)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var req db.UpdateUserRoleRequest
+
+	// Bind the ID from the URL and the role from JSON
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	violations := validator.ValidateUpdateUserRoleRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	user, err := server.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		ID:   req.ID,
+		Role: req.Role,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user role"})
+		return
+	}
+
+	rsp := newUserResponse(user)
 	ctx.JSON(http.StatusOK, rsp)
 }
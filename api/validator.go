@@ -1,15 +1,19 @@
 package api
 
 import (
-	"github.com/forabbie/vank-app/util"
-	"github.com/go-playground/validator/v10"
+	"fmt"
+	"net/http"
+
+	"github.com/forabbie/vank-app/validator"
+	"github.com/gin-gonic/gin"
 )
 
-var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
-	if currency, ok := fl.Field().Interface().(string); ok {
-		if currency == "USD" || currency == "EUR" {
-			return util.IsSupportedCurrency(currency)
-		}
+// supportedCurrency checks that the currency is enabled in the registry of the server,
+// and responds with 400 when it isn't
+func (server *Server) supportedCurrency(ctx *gin.Context, code string) bool {
+	if err := validator.ValidateCurrency(ctx, server.currencies, code); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("currency %w", err)))
+		return false
 	}
-	return false
+	return true
 }
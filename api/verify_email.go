@@ -0,0 +1,54 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type verifyEmailResponse struct {
+	IsVerified bool `json:"is_verified"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req db.VerifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.FormatValidationErrors(err))
+		return
+	}
+
+	// Validate request fields
+	violations := validator.ValidateVerifyEmailRequest(&req)
+	if len(violations) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation failed",
+			"details": violations,
+		})
+		return
+	}
+
+	result, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParams{
+		EmailId:    req.EmailId,
+		SecretCode: req.SecretCode,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid or expired verification link",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to verify email",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verifyEmailResponse{
+		IsVerified: result.User.IsEmailVerified,
+	})
+}
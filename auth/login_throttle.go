@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrTooManyLogins      = errors.New("too many failed login attempts, try again later")
+	ErrLoginLocked        = errors.New("too many failed login attempts, try again later or use the unlock link sent by email")
+)
+
+// LoginThrottle slows down and locks out failed logins, per username and per client IP.
+// It is shared by the HTTP and gRPC servers, so both apply the same policy.
+type LoginThrottle struct {
+	config          util.Config
+	store           db.Store
+	taskDistributor worker.TaskDistributor
+}
+
+// NewLoginThrottle creates a login throttle with the limits of the config
+func NewLoginThrottle(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) *LoginThrottle {
+	return &LoginThrottle{
+		config:          config,
+		store:           store,
+		taskDistributor: taskDistributor,
+	}
+}
+
+// RetryAfter returns how long the client has to wait before trying to log in to username again,
+// considering the recent failures for both the username and the client IP
+func (throttle *LoginThrottle) RetryAfter(ctx context.Context, username string, clientIP string) (time.Duration, bool, error) {
+	since := time.Now().Add(-throttle.config.LoginLockoutDuration)
+
+	userFailures, err := throttle.store.GetUsernameLoginFailures(ctx, db.GetUsernameLoginFailuresParams{
+		Username: username,
+		Since:    since,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	ipFailures, err := throttle.store.GetClientIPLoginFailures(ctx, db.GetClientIPLoginFailuresParams{
+		ClientIp: clientIP,
+		Since:    since,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	userWait, userLocked := retryAfter(
+		userFailures.Failures,
+		userFailures.LastFailedAt,
+		throttle.config.LoginMaxFailures,
+		throttle.config.LoginBaseDelay,
+		throttle.config.LoginLockoutDuration,
+	)
+	// A single IP trying many usernames is only locked out, its failures are spread over usernames anyway
+	ipWait, ipLocked := retryAfter(
+		ipFailures.Failures,
+		ipFailures.LastFailedAt,
+		throttle.config.LoginMaxIPFailures,
+		0,
+		throttle.config.LoginLockoutDuration,
+	)
+
+	return max(userWait, ipWait), userLocked || ipLocked, nil
+}
+
+// RecordFailure records a failed login. When it locks an existing user,
+// an unlock link is emailed to them.
+func (throttle *LoginThrottle) RecordFailure(ctx context.Context, username string, clientIP string, userExists bool) error {
+	arg := db.RecordLoginFailureTxParams{
+		CreateLoginFailureParams: db.CreateLoginFailureParams{
+			Username: username,
+			ClientIp: clientIP,
+		},
+		Since:       time.Now().Add(-throttle.config.LoginLockoutDuration),
+		MaxFailures: throttle.config.LoginMaxFailures,
+	}
+
+	if userExists {
+		arg.AfterLock = func(q db.Querier) error {
+			// The email is sent by the task processor once the failure has been committed
+			return throttle.taskDistributor.DistributeTaskSendUnlockAccountEmail(ctx, q, &worker.PayloadSendUnlockAccountEmail{
+				Username: username,
+			})
+		}
+	}
+
+	_, err := throttle.store.RecordLoginFailureTx(ctx, arg)
+	return err
+}
+
+// LoginError returns the error refusing an attempt made while the client has to wait
+func LoginError(locked bool) error {
+	if locked {
+		return ErrLoginLocked
+	}
+	return ErrTooManyLogins
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/forabbie/vank-app/currency"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/fx"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/util"
+)
+
+// MaxMfaChallengeAttempts is how many wrong codes a login challenge accepts before it is burned
+const MaxMfaChallengeAttempts = 5
+
+var (
+	ErrInvalidMfaToken = errors.New("invalid or expired mfa token")
+	ErrInvalidMfaCode  = errors.New("invalid two-factor code")
+	ErrMfaRequired     = errors.New("a two-factor code is required for transfers of this amount")
+)
+
+// CheckMfaCode reports whether code is a TOTP code of the user that has not been used before,
+// or, when allowed, one of their unused recovery codes. A matching code is consumed.
+func CheckMfaCode(ctx context.Context, store db.Store, encryptionKey string, user db.User, code string, allowRecoveryCode bool) (bool, error) {
+	if !user.IsMfaEnabled || !user.TotpSecret.Valid {
+		return false, nil
+	}
+
+	secret, err := util.Decrypt(encryptionKey, user.TotpSecret.String)
+	if err != nil {
+		return false, err
+	}
+
+	if step, ok := util.ValidateTOTP(secret, code, time.Now()); ok {
+		// A code stays valid for its whole window, so refuse to accept it a second time
+		rows, err := store.UpdateUserTotpStep(ctx, db.UpdateUserTotpStepParams{
+			TotpLastUsedStep: step,
+			Username:         user.Username,
+		})
+		if err != nil {
+			return false, err
+		}
+		return rows == 1, nil
+	}
+
+	if !allowRecoveryCode {
+		return false, nil
+	}
+
+	_, err = store.UseMfaRecoveryCode(ctx, db.UseMfaRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: util.HashRecoveryCode(code),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// TransferNeedsMfa reports whether a transfer of amount by username needs a fresh TOTP code:
+// it does when the amount is worth at least the configured threshold of the reference currency
+// and the user has two-factor authentication. The user is returned to check the code against.
+func TransferNeedsMfa(ctx context.Context, store db.Store, currencies *currency.Registry, config util.Config, username string, amount money.Amount) (db.User, bool, error) {
+	var user db.User
+
+	threshold := config.MfaTransferThreshold
+	if threshold <= 0 {
+		return user, false, nil
+	}
+
+	reached, err := fx.AtLeast(ctx, store, currencies, amount.Value, amount.Currency, threshold, config.MfaTransferThresholdCurrency)
+	if err != nil {
+		return user, false, err
+	}
+	if !reached {
+		return user, false, nil
+	}
+
+	user, err = store.GetUserByUsername(ctx, username)
+	if err != nil {
+		return user, false, err
+	}
+	return user, user.IsMfaEnabled, nil
+}
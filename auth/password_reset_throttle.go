@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
+)
+
+var ErrTooManyPasswordResets = errors.New("too many password reset requests, try again later")
+
+// PasswordResetThrottle limits the password reset emails requested per email and per client IP.
+// Requests for unknown emails are recorded and throttled like the others,
+// so neither the answers nor their timing reveal which emails are registered.
+type PasswordResetThrottle struct {
+	config          util.Config
+	store           db.Store
+	taskDistributor worker.TaskDistributor
+}
+
+// NewPasswordResetThrottle creates a password reset throttle with the limits of the config
+func NewPasswordResetThrottle(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) *PasswordResetThrottle {
+	return &PasswordResetThrottle{
+		config:          config,
+		store:           store,
+		taskDistributor: taskDistributor,
+	}
+}
+
+// RetryAfter returns how long the client has to wait before requesting a reset for email again,
+// considering the recent requests for both the email and the client IP
+func (throttle *PasswordResetThrottle) RetryAfter(ctx context.Context, email string, clientIP string) (time.Duration, error) {
+	since := time.Now().Add(-throttle.config.ResetPasswordWindow)
+
+	emailRequests, err := throttle.store.GetEmailPasswordResetRequests(ctx, db.GetEmailPasswordResetRequestsParams{
+		Email: strings.ToLower(email),
+		Since: since,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	ipRequests, err := throttle.store.GetClientIPPasswordResetRequests(ctx, db.GetClientIPPasswordResetRequestsParams{
+		ClientIp: clientIP,
+		Since:    since,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// There is no delay between requests, only a lockout for a window after the last one over the limit
+	emailWait, _ := retryAfter(
+		emailRequests.Requests,
+		emailRequests.LastRequestedAt,
+		throttle.config.ResetPasswordMaxRequests,
+		0,
+		throttle.config.ResetPasswordWindow,
+	)
+	ipWait, _ := retryAfter(
+		ipRequests.Requests,
+		ipRequests.LastRequestedAt,
+		throttle.config.ResetPasswordMaxIPRequests,
+		0,
+		throttle.config.ResetPasswordWindow,
+	)
+
+	return max(emailWait, ipWait), nil
+}
+
+// RecordRequest records a password reset request and has the reset code emailed.
+// The user is looked up by the task processor, so the request takes the same path
+// whether the email belongs to a user or not.
+func (throttle *PasswordResetThrottle) RecordRequest(ctx context.Context, email string, clientIP string) error {
+	arg := db.RequestPasswordResetTxParams{
+		CreatePasswordResetRequestParams: db.CreatePasswordResetRequestParams{
+			Email:    strings.ToLower(email),
+			ClientIp: clientIP,
+		},
+		AfterCreate: func(q db.Querier) error {
+			// The email is sent by the task processor once the request has been committed
+			return throttle.taskDistributor.DistributeTaskSendResetPasswordEmail(ctx, q, &worker.PayloadSendResetPasswordEmail{
+				Email: email,
+			})
+		},
+	}
+
+	_, err := throttle.store.RequestPasswordResetTx(ctx, arg)
+	return err
+}
@@ -0,0 +1,41 @@
+package auth
+
+import "time"
+
+// retryAfter returns how long a client has to wait before its next login attempt,
+// given the failures recorded for a username or client IP since the start of the lockout window.
+// Each failure doubles the delay starting from baseDelay, up to lockoutDuration; after maxFailures
+// the username or IP is locked until lockoutDuration has passed since the last failure.
+// A zero maxFailures or baseDelay turns the corresponding check off.
+func retryAfter(
+	failures int64,
+	lastFailedAt time.Time,
+	maxFailures int64,
+	baseDelay time.Duration,
+	lockoutDuration time.Duration,
+) (wait time.Duration, locked bool) {
+	if failures == 0 {
+		return 0, false
+	}
+
+	var allowedAt time.Time
+	switch {
+	case maxFailures > 0 && failures >= maxFailures:
+		locked = true
+		allowedAt = lastFailedAt.Add(lockoutDuration)
+	case baseDelay > 0:
+		delay := baseDelay
+		for i := int64(1); i < failures && delay < lockoutDuration; i++ {
+			delay *= 2
+		}
+		allowedAt = lastFailedAt.Add(min(delay, lockoutDuration))
+	default:
+		return 0, false
+	}
+
+	wait = time.Until(allowedAt)
+	if wait <= 0 {
+		return 0, false
+	}
+	return wait, locked
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Now()
+	lockout := 15 * time.Minute
+
+	testCases := []struct {
+		name         string
+		failures     int64
+		lastFailedAt time.Time
+		maxFailures  int64
+		baseDelay    time.Duration
+		wantWait     time.Duration
+		wantLocked   bool
+	}{
+		{
+			name:        "NoFailures",
+			failures:    0,
+			maxFailures: 5,
+			baseDelay:   time.Second,
+		},
+		{
+			name:         "FirstFailure",
+			failures:     1,
+			lastFailedAt: now,
+			maxFailures:  5,
+			baseDelay:    time.Second,
+			wantWait:     time.Second,
+		},
+		{
+			name:         "ProgressiveDelay",
+			failures:     4,
+			lastFailedAt: now,
+			maxFailures:  5,
+			baseDelay:    time.Second,
+			wantWait:     8 * time.Second,
+		},
+		{
+			name:         "DelayElapsed",
+			failures:     2,
+			lastFailedAt: now.Add(-time.Minute),
+			maxFailures:  5,
+			baseDelay:    time.Second,
+		},
+		{
+			name:         "DelayCappedAtLockout",
+			failures:     40,
+			lastFailedAt: now,
+			baseDelay:    time.Minute,
+			wantWait:     lockout,
+		},
+		{
+			name:         "Locked",
+			failures:     5,
+			lastFailedAt: now,
+			maxFailures:  5,
+			baseDelay:    time.Second,
+			wantWait:     lockout,
+			wantLocked:   true,
+		},
+		{
+			name:         "LockoutElapsed",
+			failures:     5,
+			lastFailedAt: now.Add(-lockout),
+			maxFailures:  5,
+			baseDelay:    time.Second,
+		},
+		{
+			name:         "Disabled",
+			failures:     10,
+			lastFailedAt: now,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			wait, locked := retryAfter(tc.failures, tc.lastFailedAt, tc.maxFailures, tc.baseDelay, lockout)
+			require.Equal(t, tc.wantLocked, locked)
+			require.InDelta(t, tc.wantWait, wait, float64(time.Second))
+		})
+	}
+}
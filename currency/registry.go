@@ -0,0 +1,132 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/money"
+)
+
+// ErrUnknownCurrency is returned for currencies that are not in the currencies table
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Registry is a cache of the currencies table.
+// It reloads the table once the cache is older than its TTL, so a currency
+// enabled by an admin is picked up by every server without a redeploy.
+type Registry struct {
+	store db.Querier
+	ttl   time.Duration
+
+	mu         sync.RWMutex
+	currencies map[string]db.Currency
+	loadedAt   time.Time
+}
+
+// NewRegistry creates a registry that loads currencies from the store and keeps them for ttl
+func NewRegistry(store db.Querier, ttl time.Duration) *Registry {
+	return &Registry{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Get returns the currency with the given code, whether it is enabled or not
+func (registry *Registry) Get(ctx context.Context, code string) (db.Currency, bool) {
+	currencies := registry.load(ctx)
+	currency, ok := currencies[code]
+	return currency, ok
+}
+
+// IsSupported reports whether accounts and transfers can use the currency
+func (registry *Registry) IsSupported(ctx context.Context, code string) bool {
+	currency, ok := registry.Get(ctx, code)
+	return ok && currency.IsEnabled
+}
+
+// Exponent returns the number of digits after the decimal separator of a currency
+func (registry *Registry) Exponent(ctx context.Context, code string) (int32, error) {
+	currency, ok := registry.Get(ctx, code)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return currency.Exponent, nil
+}
+
+// Amount returns an amount of minor units of a currency, formatted with the exponent of the table
+func (registry *Registry) Amount(ctx context.Context, value int64, code string) (money.Amount, error) {
+	exponent, err := registry.Exponent(ctx, code)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	return money.New(value, code, exponent), nil
+}
+
+// Parse parses a decimal string such as "12.34" into an amount of a currency
+func (registry *Registry) Parse(ctx context.Context, s string, code string) (money.Amount, error) {
+	exponent, err := registry.Exponent(ctx, code)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	return money.Parse(s, code, exponent)
+}
+
+// Load reads the currencies table straight away. Servers call it on startup,
+// so that they don't run without knowing the exponents of the currencies.
+func (registry *Registry) Load(ctx context.Context) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	return registry.reload(ctx)
+}
+
+// Invalidate makes the next lookup reload the currencies, after they were changed by this server
+func (registry *Registry) Invalidate() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.loadedAt = time.Time{}
+}
+
+func (registry *Registry) load(ctx context.Context) map[string]db.Currency {
+	registry.mu.RLock()
+	currencies, loadedAt := registry.currencies, registry.loadedAt
+	registry.mu.RUnlock()
+
+	if currencies != nil && time.Since(loadedAt) < registry.ttl {
+		return currencies
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	// Another request may have reloaded them while we were waiting for the lock
+	if registry.currencies != nil && time.Since(registry.loadedAt) < registry.ttl {
+		return registry.currencies
+	}
+
+	if err := registry.reload(ctx); err != nil {
+		// Keep serving the last known currencies rather than rejecting every request
+		log.Printf("cannot reload currencies: %s", err)
+	}
+	return registry.currencies
+}
+
+// reload replaces the cached currencies with the table, the caller must hold the write lock
+func (registry *Registry) reload(ctx context.Context) error {
+	list, err := registry.store.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	registry.currencies = make(map[string]db.Currency, len(list))
+	for _, currency := range list {
+		registry.currencies[currency.Code] = currency
+	}
+	registry.loadedAt = time.Now()
+	return nil
+}
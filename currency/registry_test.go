@@ -0,0 +1,130 @@
+package currency
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	registry := NewRegistry(store, time.Minute)
+
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return([]db.Currency{
+			{Code: "USD", Exponent: 2, IsEnabled: true},
+			{Code: "JPY", Exponent: 0, IsEnabled: false},
+		}, nil)
+
+	// Loaded once, then served from the cache
+	require.True(t, registry.IsSupported(context.Background(), "USD"))
+	require.False(t, registry.IsSupported(context.Background(), "JPY"))
+	require.False(t, registry.IsSupported(context.Background(), "GBP"))
+
+	jpy, ok := registry.Get(context.Background(), "JPY")
+	require.True(t, ok)
+	require.Equal(t, int32(0), jpy.Exponent)
+
+	// amounts are formatted with the exponents of the table
+	amount, err := registry.Amount(context.Background(), 1500, "JPY")
+	require.NoError(t, err)
+	require.Equal(t, "1500", amount.String())
+
+	amount, err = registry.Parse(context.Background(), "10.50", "USD")
+	require.NoError(t, err)
+	require.Equal(t, int64(1050), amount.Value)
+
+	// instead of guessing the exponent of a currency that isn't in the table
+	_, err = registry.Amount(context.Background(), 1500, "GBP")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+	_, err = registry.Parse(context.Background(), "10.50", "GBP")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+
+	// An admin enables JPY
+	registry.Invalidate()
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return([]db.Currency{
+			{Code: "USD", Exponent: 2, IsEnabled: true},
+			{Code: "JPY", Exponent: 0, IsEnabled: true},
+		}, nil)
+
+	require.True(t, registry.IsSupported(context.Background(), "JPY"))
+}
+
+func TestRegistryExpires(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	registry := NewRegistry(store, time.Millisecond)
+
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return([]db.Currency{{Code: "USD", Exponent: 2, IsEnabled: true}}, nil)
+	require.True(t, registry.IsSupported(context.Background(), "USD"))
+
+	time.Sleep(2 * time.Millisecond)
+
+	// The last known currencies are kept when they can't be reloaded
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+	require.True(t, registry.IsSupported(context.Background(), "USD"))
+}
+
+func TestRegistryUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	registry := NewRegistry(store, time.Minute)
+
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(2).
+		Return(nil, sql.ErrConnDone)
+
+	// Nothing is supported until the currencies could be loaded, and loading is retried
+	require.False(t, registry.IsSupported(context.Background(), "USD"))
+	require.False(t, registry.IsSupported(context.Background(), "USD"))
+}
+
+func TestRegistryLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	registry := NewRegistry(store, time.Minute)
+
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+	require.ErrorIs(t, registry.Load(context.Background()), sql.ErrConnDone)
+
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return([]db.Currency{{Code: "JPY", Exponent: 0, IsEnabled: true}}, nil)
+	require.NoError(t, registry.Load(context.Background()))
+
+	// served from the cache
+	exponent, err := registry.Exponent(context.Background(), "JPY")
+	require.NoError(t, err)
+	require.Equal(t, int32(0), exponent)
+}
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "users" DROP CONSTRAINT IF EXISTS "role_supported";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "unlock_accounts";
+DROP TABLE IF EXISTS "login_failures";
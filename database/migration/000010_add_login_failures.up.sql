@@ -0,0 +1,23 @@
+CREATE TABLE "login_failures" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "login_failures" ("username", "created_at");
+
+CREATE INDEX ON "login_failures" ("client_ip", "created_at");
+
+COMMENT ON COLUMN "login_failures"."username" IS 'as typed by the client, so unknown usernames are throttled like real ones';
+
+CREATE TABLE "unlock_accounts" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "secret_code" varchar NOT NULL,
+  "is_used" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '15 minutes')
+);
+
+ALTER TABLE "unlock_accounts" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,17 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "exponent" int NOT NULL,
+  "is_enabled" bool NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of digits after the decimal separator, amounts are stored in minor units';
+
+INSERT INTO "currencies" ("code", "exponent") VALUES
+  ('USD', 2),
+  ('EUR', 2),
+  ('CAD', 2);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
@@ -0,0 +1,32 @@
+CREATE TABLE "fx_rates" (
+  "id" bigserial PRIMARY KEY,
+  "base" varchar(3) NOT NULL,
+  "quote" varchar(3) NOT NULL,
+  "rate" numeric NOT NULL,
+  "valid_from" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fx_rates" ADD FOREIGN KEY ("base") REFERENCES "currencies" ("code");
+
+ALTER TABLE "fx_rates" ADD FOREIGN KEY ("quote") REFERENCES "currencies" ("code");
+
+ALTER TABLE "fx_rates" ADD CONSTRAINT "fx_rate_positive" CHECK ("rate" > 0);
+
+CREATE UNIQUE INDEX ON "fx_rates" ("base", "quote", "valid_from");
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'how many units of quote one unit of base buys';
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "fx_rate" numeric NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the currency of the source account';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited, in the currency of the destination account';
+
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'rate applied to convert amount into to_amount, 1 for same-currency transfers';
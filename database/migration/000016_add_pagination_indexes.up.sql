@@ -0,0 +1,11 @@
+-- Keyset pagination reads each listing in (created_at, id) order
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+-- superseded by the index above
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
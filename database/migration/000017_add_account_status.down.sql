@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "closed_balance_zero";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "closed_at";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "status_supported";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
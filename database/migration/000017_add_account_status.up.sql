@@ -0,0 +1,10 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "status_supported" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+ALTER TABLE "accounts" ADD COLUMN "closed_at" timestamptz;
+
+-- Closed accounts keep their history, so they can't hold money
+ALTER TABLE "accounts" ADD CONSTRAINT "closed_balance_zero" CHECK ("status" <> 'closed' OR "balance" = 0);
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen (no money moves) or closed (for good)';
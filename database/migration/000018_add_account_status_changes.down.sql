@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "account_status_changes";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "freeze_blocks_incoming";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "frozen_reason";
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen (no money moves) or closed (for good)';
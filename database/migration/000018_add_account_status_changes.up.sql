@@ -0,0 +1,23 @@
+ALTER TABLE "accounts" ADD COLUMN "frozen_reason" varchar;
+
+ALTER TABLE "accounts" ADD COLUMN "freeze_blocks_incoming" bool NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen (sends no money) or closed (for good)';
+
+COMMENT ON COLUMN "accounts"."freeze_blocks_incoming" IS 'a frozen account never sends money, and receives none either when set';
+
+CREATE TABLE "account_status_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "changed_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
+
+CREATE INDEX ON "account_status_changes" ("account_id", "created_at");
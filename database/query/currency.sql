@@ -0,0 +1,22 @@
+-- name: CreateCurrency :one
+INSERT INTO currencies (
+  code,
+  exponent,
+  is_enabled
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = $1 LIMIT 1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
+
+-- name: UpdateCurrencyEnabled :one
+UPDATE currencies
+SET is_enabled = sqlc.arg(is_enabled)
+WHERE code = sqlc.arg(code)
+RETURNING *;
@@ -0,0 +1,17 @@
+-- name: CreateFxRate :exec
+INSERT INTO fx_rates (
+  base,
+  quote,
+  rate,
+  valid_from
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (base, quote, valid_from) DO NOTHING;
+
+-- name: GetLatestFxRate :one
+SELECT * FROM fx_rates
+WHERE base = sqlc.arg(base)
+  AND quote = sqlc.arg(quote)
+  AND valid_from <= sqlc.arg(at)
+ORDER BY valid_from DESC
+LIMIT 1;
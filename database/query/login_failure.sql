@@ -0,0 +1,29 @@
+-- name: CreateLoginFailure :one
+INSERT INTO login_failures (
+  username,
+  client_ip
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: GetUsernameLoginFailures :one
+SELECT
+  count(*) AS failures,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_failed_at
+FROM login_failures
+WHERE
+  username = sqlc.arg(username) AND
+  created_at > sqlc.arg(since);
+
+-- name: GetClientIPLoginFailures :one
+SELECT
+  count(*) AS failures,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_failed_at
+FROM login_failures
+WHERE
+  client_ip = sqlc.arg(client_ip) AND
+  created_at > sqlc.arg(since);
+
+-- name: DeleteLoginFailures :exec
+DELETE FROM login_failures
+WHERE username = $1;
@@ -0,0 +1,47 @@
+-- name: CreateMfaRecoveryCode :one
+INSERT INTO mfa_recovery_codes (
+  username,
+  hashed_code
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: DeleteMfaRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE username = $1;
+
+-- name: UseMfaRecoveryCode :one
+UPDATE mfa_recovery_codes
+SET used_at = now()
+WHERE
+  username = sqlc.arg(username) AND
+  hashed_code = sqlc.arg(hashed_code) AND
+  used_at IS NULL
+RETURNING *;
+
+-- name: CreateMfaChallenge :one
+INSERT INTO mfa_challenges (
+  id,
+  username,
+  expires_at
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetMfaChallenge :one
+SELECT * FROM mfa_challenges
+WHERE id = $1 LIMIT 1;
+
+-- name: FailMfaChallenge :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: UseMfaChallenge :one
+UPDATE mfa_challenges
+SET is_used = true
+WHERE
+  id = $1 AND
+  is_used = false
+RETURNING *;
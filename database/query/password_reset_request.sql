@@ -0,0 +1,25 @@
+-- name: CreatePasswordResetRequest :one
+INSERT INTO password_reset_requests (
+  email,
+  client_ip
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: GetEmailPasswordResetRequests :one
+SELECT
+  count(*) AS requests,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_requested_at
+FROM password_reset_requests
+WHERE
+  email = sqlc.arg(email) AND
+  created_at > sqlc.arg(since);
+
+-- name: GetClientIPPasswordResetRequests :one
+SELECT
+  count(*) AS requests,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_requested_at
+FROM password_reset_requests
+WHERE
+  client_ip = sqlc.arg(client_ip) AND
+  created_at > sqlc.arg(since);
@@ -0,0 +1,19 @@
+-- name: CreateResetPassword :one
+INSERT INTO reset_passwords (
+    username,
+    email,
+    secret_code
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: UpdateResetPassword :one
+UPDATE reset_passwords
+SET
+    is_used = TRUE
+WHERE
+    id = @id
+    AND secret_code = @secret_code
+    AND is_used = FALSE
+    AND expired_at > now()
+RETURNING *;
@@ -6,11 +6,57 @@ INSERT INTO sessions (
   user_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
+
+-- name: ListActiveSessions :many
+SELECT * FROM sessions
+WHERE
+  username = $1 AND
+  is_blocked = false AND
+  is_rotated = false AND
+  expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = true
+WHERE
+  id = sqlc.arg(id) AND
+  username = sqlc.arg(username)
+RETURNING *;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE
+  username = $1 AND
+  is_blocked = false;
+
+-- name: RotateSession :one
+UPDATE sessions
+SET is_rotated = true
+WHERE
+  id = $1 AND
+  is_rotated = false
+RETURNING *;
+
+-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1;
+
+-- name: BlockUserSessionsExcept :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE
+  username = sqlc.arg(username) AND
+  is_blocked = false AND
+  (sqlc.narg(keep_session_id)::uuid IS NULL OR id <> sqlc.narg(keep_session_id));
@@ -0,0 +1,51 @@
+-- name: CreateTask :one
+INSERT INTO tasks (
+  type,
+  payload,
+  max_attempts,
+  run_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetTask :one
+SELECT * FROM tasks
+WHERE id = $1 LIMIT 1;
+
+-- name: ClaimTasks :many
+UPDATE tasks
+SET
+  attempts = attempts + 1,
+  run_at = sqlc.arg(lease_expires_at),
+  updated_at = now()
+WHERE id IN (
+  SELECT id FROM tasks
+  WHERE status = 'pending' AND run_at <= now()
+  ORDER BY run_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteTask :exec
+UPDATE tasks
+SET
+  status = 'completed',
+  updated_at = now()
+WHERE id = $1;
+
+-- name: RetryTask :exec
+UPDATE tasks
+SET
+  run_at = sqlc.arg(run_at),
+  last_error = sqlc.arg(last_error),
+  updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: DeadLetterTask :exec
+UPDATE tasks
+SET
+  status = 'dead',
+  last_error = sqlc.arg(last_error),
+  updated_at = now()
+WHERE id = sqlc.arg(id);
@@ -2,9 +2,11 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  fx_rate
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -12,10 +14,98 @@ SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
 -- name: ListTransfers :many
-SELECT * FROM transfers
-WHERE 
-  from_account_id = $1 OR 
-  to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4;
+SELECT sqlc.embed(transfers), from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (transfers.from_account_id = sqlc.arg(from_account_id) OR transfers.to_account_id = sqlc.arg(to_account_id))
+  -- unless the viewer may read any account, each row must involve one of their accounts
+  AND (sqlc.narg(viewer)::varchar IS NULL OR from_account.owner = sqlc.narg(viewer) OR to_account.owner = sqlc.narg(viewer))
+ORDER BY transfers.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListTransfersAfter :many
+SELECT sqlc.embed(transfers), from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (transfers.from_account_id = sqlc.arg(from_account_id) OR transfers.to_account_id = sqlc.arg(to_account_id))
+  AND (sqlc.narg(viewer)::varchar IS NULL OR from_account.owner = sqlc.narg(viewer) OR to_account.owner = sqlc.narg(viewer))
+  AND (transfers.created_at, transfers.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY transfers.created_at, transfers.id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListTransfersByIDs :many
+SELECT sqlc.embed(transfers), from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE transfers.id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY transfers.id;
+
+-- name: SearchAccountTransfers :many
+-- Transfers in and out of an account, oldest first. Each direction is served by
+-- the (from_account_id, created_at, id) and (to_account_id, created_at, id) indexes,
+-- and amounts are compared in the currency of the account.
+SELECT sqlc.embed(transfers), from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (
+    (
+      sqlc.arg(outgoing)::boolean
+      AND transfers.from_account_id = sqlc.arg(account_id)
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR transfers.to_account_id = sqlc.narg(counterparty_id))
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR transfers.amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR transfers.amount <= sqlc.narg(max_amount))
+    ) OR (
+      sqlc.arg(incoming)::boolean
+      AND transfers.to_account_id = sqlc.arg(account_id)
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR transfers.from_account_id = sqlc.narg(counterparty_id))
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR transfers.to_amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR transfers.to_amount <= sqlc.narg(max_amount))
+    )
+  )
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR transfers.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR transfers.created_at < sqlc.narg(to_time))
+  AND (
+    sqlc.narg(after_created_at)::timestamptz IS NULL OR
+    (transfers.created_at, transfers.id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint)
+  )
+ORDER BY transfers.created_at, transfers.id
+LIMIT sqlc.arg(page_size);
+
+-- name: SearchAccountTransfersDesc :many
+-- Same as SearchAccountTransfers, newest first
+SELECT sqlc.embed(transfers), from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (
+    (
+      sqlc.arg(outgoing)::boolean
+      AND transfers.from_account_id = sqlc.arg(account_id)
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR transfers.to_account_id = sqlc.narg(counterparty_id))
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR transfers.amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR transfers.amount <= sqlc.narg(max_amount))
+    ) OR (
+      sqlc.arg(incoming)::boolean
+      AND transfers.to_account_id = sqlc.arg(account_id)
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR transfers.from_account_id = sqlc.narg(counterparty_id))
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR transfers.to_amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR transfers.to_amount <= sqlc.narg(max_amount))
+    )
+  )
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR transfers.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR transfers.created_at < sqlc.narg(to_time))
+  AND (
+    sqlc.narg(after_created_at)::timestamptz IS NULL OR
+    (transfers.created_at, transfers.id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint)
+  )
+ORDER BY transfers.created_at DESC, transfers.id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,18 @@
+-- name: CreateUnlockAccount :one
+INSERT INTO unlock_accounts (
+    username,
+    secret_code
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: UpdateUnlockAccount :one
+UPDATE unlock_accounts
+SET
+    is_used = TRUE
+WHERE
+    id = @id
+    AND secret_code = @secret_code
+    AND is_used = FALSE
+    AND expired_at > now()
+RETURNING *;
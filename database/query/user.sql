@@ -16,6 +16,10 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
 -- name: UpdateUser :one
 UPDATE users
 SET
@@ -27,3 +31,38 @@ SET
 WHERE
   id = sqlc.arg(id)
 RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = sqlc.arg(role)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetUserAuthorization :one
+SELECT password_changed_at, role FROM users
+WHERE username = $1 LIMIT 1;
+
+-- name: SetUserTotpSecret :one
+UPDATE users
+SET totp_secret = sqlc.arg(totp_secret)
+WHERE
+  username = sqlc.arg(username) AND
+  is_mfa_enabled = false
+RETURNING *;
+
+-- name: EnableUserMfa :one
+UPDATE users
+SET
+  is_mfa_enabled = true,
+  totp_last_used_step = sqlc.arg(totp_last_used_step)
+WHERE
+  username = sqlc.arg(username) AND
+  totp_secret IS NOT NULL
+RETURNING *;
+
+-- name: UpdateUserTotpStep :execrows
+UPDATE users
+SET totp_last_used_step = sqlc.arg(totp_last_used_step)
+WHERE
+  username = sqlc.arg(username) AND
+  totp_last_used_step < sqlc.arg(totp_last_used_step);
@@ -0,0 +1,36 @@
+package db
+
+import "errors"
+
+// Statuses an account can have
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Errors returned when money can't move in or out of an account because of its status
+var (
+	ErrAccountFrozen = errors.New("account is frozen")
+	ErrAccountClosed = errors.New("account is closed")
+)
+
+// checkActive returns an error unless money can move in and out of the account
+func checkActive(account Account) error {
+	switch account.Status {
+	case AccountStatusFrozen:
+		return ErrAccountFrozen
+	case AccountStatusClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// checkCanReceive returns an error unless money can move into the account.
+// A frozen account keeps receiving unless its freeze blocks incoming transfers too.
+func checkCanReceive(account Account) error {
+	if account.Status == AccountStatusFrozen && !account.FreezeBlocksIncoming {
+		return nil
+	}
+	return checkActive(account)
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createOwnAccounts(t *testing.T, balance int64) (usdAccount Account, eurAccount Account) {
+	user := createRandomUser(t)
+
+	usdAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	eurAccount, err = testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: util.EUR,
+	})
+	require.NoError(t, err)
+	return
+}
+
+func TestCloseAccountTxZeroBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account, _ := createOwnAccounts(t, 0)
+	require.Equal(t, AccountStatusActive, account.Status)
+	require.False(t, account.ClosedAt.Valid)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID, ChangedBy: account.Owner})
+	require.NoError(t, err)
+	require.Nil(t, result.Sweep)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.True(t, result.Account.ClosedAt.Valid)
+
+	// a closed account stays closed
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID, ChangedBy: account.Owner})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
+
+func TestCloseAccountTxSweep(t *testing.T) {
+	store := NewStore(testDB)
+	usdAccount, eurAccount := createOwnAccounts(t, 1000)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        usdAccount.ID,
+		SweepToAccountID: eurAccount.ID,
+		Convert: func(q Querier, amount int64, from string, to string) (int64, string, error) {
+			require.Equal(t, int64(1000), amount)
+			require.Equal(t, util.USD, from)
+			require.Equal(t, util.EUR, to)
+			return 920, "0.92", nil
+		},
+		Reason:    "moving abroad",
+		ChangedBy: usdAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, int64(1000), result.Sweep.Transfer.Amount)
+	require.Equal(t, int64(920), result.Sweep.Transfer.ToAmount)
+	require.Equal(t, int64(920), result.Sweep.ToAccount.Balance)
+
+	changes, err := store.ListAccountStatusChanges(context.Background(), usdAccount.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "moving abroad", changes[0].Reason)
+	require.Equal(t, usdAccount.Owner, changes[0].ChangedBy)
+}
+
+func TestCloseAccountTxBalanceNotZero(t *testing.T) {
+	store := NewStore(testDB)
+	usdAccount, eurAccount := createOwnAccounts(t, 1000)
+
+	// money must go somewhere
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: usdAccount.ID, ChangedBy: usdAccount.Owner})
+	require.ErrorIs(t, err, ErrBalanceNotZero)
+
+	// and a debt must be paid back first
+	eurAccount, err = store.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             eurAccount.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+	_, err = store.FxTransferTx(context.Background(), FxTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: eurAccount.ID,
+			ToAccountID:   usdAccount.ID,
+			Amount:        100,
+		},
+		ToAmount: 108,
+		FxRate:   "1.08",
+	})
+	require.NoError(t, err)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        eurAccount.ID,
+		SweepToAccountID: usdAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrBalanceNotZero)
+
+	account, err := store.GetAccount(context.Background(), eurAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, account.Status)
+}
+
+func TestCloseAccountTxInvalidSweepAccount(t *testing.T) {
+	store := NewStore(testDB)
+	usdAccount, eurAccount := createOwnAccounts(t, 1000)
+	otherAccount := createRandomAccount(t)
+
+	testCases := []struct {
+		name string
+		arg  CloseAccountTxParams
+	}{
+		{
+			name: "SameAccount",
+			arg:  CloseAccountTxParams{AccountID: usdAccount.ID, SweepToAccountID: usdAccount.ID},
+		},
+		{
+			name: "OtherOwner",
+			arg:  CloseAccountTxParams{AccountID: usdAccount.ID, SweepToAccountID: otherAccount.ID},
+		},
+		{
+			name: "NoConversion",
+			arg:  CloseAccountTxParams{AccountID: usdAccount.ID, SweepToAccountID: eurAccount.ID},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.CloseAccountTx(context.Background(), tc.arg)
+			require.ErrorIs(t, err, ErrInvalidSweepAccount)
+		})
+	}
+
+	// nothing should have been written
+	account, err := store.GetAccount(context.Background(), usdAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, usdAccount.Balance, account.Balance)
+	require.Equal(t, AccountStatusActive, account.Status)
+}
+
+func TestTransferTxClosedAccount(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2, _ := createOwnAccounts(t, 0)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account2.ID, ChangedBy: account2.Owner})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+	// seeded by the migration
+	require.Subset(t, codes, []string{util.CAD, util.EUR, util.USD})
+}
+
+func TestCreateAndUpdateCurrency(t *testing.T) {
+	// lowercase, so it never clashes with the seeded currencies
+	code := util.RandomString(3)
+
+	currency, err := testQueries.CreateCurrency(context.Background(), CreateCurrencyParams{
+		Code:      code,
+		Exponent:  0,
+		IsEnabled: false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, code, currency.Code)
+	require.False(t, currency.IsEnabled)
+
+	currency, err = testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:      code,
+		IsEnabled: true,
+	})
+	require.NoError(t, err)
+	require.True(t, currency.IsEnabled)
+
+	_, err = testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:      "???",
+		IsEnabled: true,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -69,3 +69,41 @@ func TestListEntries(t *testing.T) {
 		require.NotEmpty(t, entry)
 	}
 }
+
+func TestListEntriesAfter(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var created []int64
+	for i := 0; i < 10; i++ {
+		entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    util.RandomMoney(),
+		})
+		require.NoError(t, err)
+		created = append(created, entry.ID)
+	}
+
+	// following the last row of each page visits every entry exactly once
+	var listed []int64
+	arg := ListEntriesAfterParams{
+		AccountID: account.ID,
+		PageSize:  4,
+	}
+	for {
+		entries, err := testQueries.ListEntriesAfter(context.Background(), arg)
+		require.NoError(t, err)
+		if len(entries) == 0 {
+			break
+		}
+
+		for _, entry := range entries {
+			listed = append(listed, entry.ID)
+		}
+
+		last := entries[len(entries)-1]
+		arg.AfterCreatedAt = last.CreatedAt
+		arg.AfterID = last.ID
+	}
+
+	require.Equal(t, created, listed)
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.FreezeAccountTx(context.Background(), FreezeAccountTxParams{
+		AccountID: account1.ID,
+		Reason:    "suspicious activity",
+		ChangedBy: admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, result.Account.Status)
+	require.Equal(t, "suspicious activity", result.Account.FrozenReason.String)
+	require.False(t, result.Account.FreezeBlocksIncoming)
+
+	require.Equal(t, account1.ID, result.Change.AccountID)
+	require.Equal(t, AccountStatusActive, result.Change.FromStatus)
+	require.Equal(t, AccountStatusFrozen, result.Change.ToStatus)
+	require.Equal(t, admin.Username, result.Change.ChangedBy)
+
+	// a frozen account can't send money
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// but still receives it
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	// unless the freeze blocks that too
+	_, err = store.FreezeAccountTx(context.Background(), FreezeAccountTxParams{
+		AccountID:     account1.ID,
+		Reason:        "court order",
+		BlockIncoming: true,
+		ChangedBy:     admin.Username,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+}
+
+func TestUnfreezeAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// only a frozen account can be unfrozen
+	_, err := store.UnfreezeAccountTx(context.Background(), UnfreezeAccountTxParams{
+		AccountID: account1.ID,
+		Reason:    "cleared by compliance",
+		ChangedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrAccountNotFrozen)
+
+	_, err = store.FreezeAccountTx(context.Background(), FreezeAccountTxParams{
+		AccountID:     account1.ID,
+		Reason:        "suspicious activity",
+		BlockIncoming: true,
+		ChangedBy:     admin.Username,
+	})
+	require.NoError(t, err)
+
+	result, err := store.UnfreezeAccountTx(context.Background(), UnfreezeAccountTxParams{
+		AccountID: account1.ID,
+		Reason:    "cleared by compliance",
+		ChangedBy: admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, result.Account.Status)
+	require.False(t, result.Account.FrozenReason.Valid)
+	require.False(t, result.Account.FreezeBlocksIncoming)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	// every change is in the audit trail, oldest first
+	changes, err := store.ListAccountStatusChanges(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, "suspicious activity", changes[0].Reason)
+	require.Equal(t, AccountStatusFrozen, changes[0].ToStatus)
+	require.Equal(t, "cleared by compliance", changes[1].Reason)
+	require.Equal(t, AccountStatusActive, changes[1].ToStatus)
+}
+
+func TestFreezeAccountTxClosedAccount(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account, _ := createOwnAccounts(t, 0)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID, ChangedBy: account.Owner})
+	require.NoError(t, err)
+
+	_, err = store.FreezeAccountTx(context.Background(), FreezeAccountTxParams{
+		AccountID: account.ID,
+		Reason:    "suspicious activity",
+		ChangedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	// the closure is audited too
+	changes, err := store.ListAccountStatusChanges(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, AccountStatusClosed, changes[0].ToStatus)
+	require.Equal(t, account.Owner, changes[0].ChangedBy)
+}
@@ -0,0 +1,68 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: fx_rate.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createFxRate = `-- name: CreateFxRate :exec
+INSERT INTO fx_rates (
+  base,
+  quote,
+  rate,
+  valid_from
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (base, quote, valid_from) DO NOTHING
+`
+
+type CreateFxRateParams struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      string    `json:"rate"`
+	ValidFrom time.Time `json:"valid_from"`
+}
+
+func (q *Queries) CreateFxRate(ctx context.Context, arg CreateFxRateParams) error {
+	_, err := q.db.ExecContext(ctx, createFxRate,
+		arg.Base,
+		arg.Quote,
+		arg.Rate,
+		arg.ValidFrom,
+	)
+	return err
+}
+
+const getLatestFxRate = `-- name: GetLatestFxRate :one
+SELECT id, base, quote, rate, valid_from, created_at FROM fx_rates
+WHERE base = $1
+  AND quote = $2
+  AND valid_from <= $3
+ORDER BY valid_from DESC
+LIMIT 1
+`
+
+type GetLatestFxRateParams struct {
+	Base  string    `json:"base"`
+	Quote string    `json:"quote"`
+	At    time.Time `json:"at"`
+}
+
+func (q *Queries) GetLatestFxRate(ctx context.Context, arg GetLatestFxRateParams) (FxRate, error) {
+	row := q.db.QueryRowContext(ctx, getLatestFxRate, arg.Base, arg.Quote, arg.At)
+	var i FxRate
+	err := row.Scan(
+		&i.ID,
+		&i.Base,
+		&i.Quote,
+		&i.Rate,
+		&i.ValidFrom,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLatestFxRate(t *testing.T) {
+	// rates are only ever added, so start from a point in time no other test uses
+	now := time.Now().Add(time.Duration(util.RandomInt(1, 1000000)) * time.Hour).UTC().Truncate(time.Second)
+
+	err := testQueries.CreateFxRate(context.Background(), CreateFxRateParams{
+		Base:      util.USD,
+		Quote:     util.CAD,
+		Rate:      "1.35",
+		ValidFrom: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	err = testQueries.CreateFxRate(context.Background(), CreateFxRateParams{
+		Base:      util.USD,
+		Quote:     util.CAD,
+		Rate:      "1.36",
+		ValidFrom: now,
+	})
+	require.NoError(t, err)
+
+	// a rate that only becomes valid later must not be applied yet
+	err = testQueries.CreateFxRate(context.Background(), CreateFxRateParams{
+		Base:      util.USD,
+		Quote:     util.CAD,
+		Rate:      "1.40",
+		ValidFrom: now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	latest, err := testQueries.GetLatestFxRate(context.Background(), GetLatestFxRateParams{
+		Base:  util.USD,
+		Quote: util.CAD,
+		At:    now.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.36", latest.Rate)
+	require.WithinDuration(t, now, latest.ValidFrom, time.Second)
+
+	// loading another rate for the same time keeps the stored one instead of failing
+	err = testQueries.CreateFxRate(context.Background(), CreateFxRateParams{
+		Base:      util.USD,
+		Quote:     util.CAD,
+		Rate:      "1.37",
+		ValidFrom: now,
+	})
+	require.NoError(t, err)
+
+	rate, err := testQueries.GetLatestFxRate(context.Background(), GetLatestFxRateParams{
+		Base:  util.USD,
+		Quote: util.CAD,
+		At:    now.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, latest, rate)
+
+	_, err = testQueries.GetLatestFxRate(context.Background(), GetLatestFxRateParams{
+		Base:  util.CAD,
+		Quote: util.USD,
+		At:    time.Unix(0, 0),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,101 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: login_failure.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createLoginFailure = `-- name: CreateLoginFailure :one
+INSERT INTO login_failures (
+  username,
+  client_ip
+) VALUES (
+  $1, $2
+) RETURNING id, username, client_ip, created_at
+`
+
+type CreateLoginFailureParams struct {
+	Username string `json:"username"`
+	ClientIp string `json:"client_ip"`
+}
+
+func (q *Queries) CreateLoginFailure(ctx context.Context, arg CreateLoginFailureParams) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, createLoginFailure, arg.Username, arg.ClientIp)
+	var i LoginFailure
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.ClientIp,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteLoginFailures = `-- name: DeleteLoginFailures :exec
+DELETE FROM login_failures
+WHERE username = $1
+`
+
+func (q *Queries) DeleteLoginFailures(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, deleteLoginFailures, username)
+	return err
+}
+
+const getClientIPLoginFailures = `-- name: GetClientIPLoginFailures :one
+SELECT
+  count(*) AS failures,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_failed_at
+FROM login_failures
+WHERE
+  client_ip = $1 AND
+  created_at > $2
+`
+
+type GetClientIPLoginFailuresParams struct {
+	ClientIp string    `json:"client_ip"`
+	Since    time.Time `json:"since"`
+}
+
+type GetClientIPLoginFailuresRow struct {
+	Failures     int64     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+func (q *Queries) GetClientIPLoginFailures(ctx context.Context, arg GetClientIPLoginFailuresParams) (GetClientIPLoginFailuresRow, error) {
+	row := q.db.QueryRowContext(ctx, getClientIPLoginFailures, arg.ClientIp, arg.Since)
+	var i GetClientIPLoginFailuresRow
+	err := row.Scan(&i.Failures, &i.LastFailedAt)
+	return i, err
+}
+
+const getUsernameLoginFailures = `-- name: GetUsernameLoginFailures :one
+SELECT
+  count(*) AS failures,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_failed_at
+FROM login_failures
+WHERE
+  username = $1 AND
+  created_at > $2
+`
+
+type GetUsernameLoginFailuresParams struct {
+	Username string    `json:"username"`
+	Since    time.Time `json:"since"`
+}
+
+type GetUsernameLoginFailuresRow struct {
+	Failures     int64     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+func (q *Queries) GetUsernameLoginFailures(ctx context.Context, arg GetUsernameLoginFailuresParams) (GetUsernameLoginFailuresRow, error) {
+	row := q.db.QueryRowContext(ctx, getUsernameLoginFailures, arg.Username, arg.Since)
+	var i GetUsernameLoginFailuresRow
+	err := row.Scan(&i.Failures, &i.LastFailedAt)
+	return i, err
+}
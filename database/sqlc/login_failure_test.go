@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoginFailures(t *testing.T) {
+	username := util.RandomOwner()
+	clientIP := util.RandomString(12)
+	since := time.Now().Add(-time.Minute)
+
+	stats, err := testQueries.GetUsernameLoginFailures(context.Background(), GetUsernameLoginFailuresParams{
+		Username: username,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.Failures)
+
+	for i := 0; i < 3; i++ {
+		_, err := testQueries.CreateLoginFailure(context.Background(), CreateLoginFailureParams{
+			Username: username,
+			ClientIp: clientIP,
+		})
+		require.NoError(t, err)
+	}
+
+	stats, err = testQueries.GetUsernameLoginFailures(context.Background(), GetUsernameLoginFailuresParams{
+		Username: username,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), stats.Failures)
+	require.WithinDuration(t, time.Now(), stats.LastFailedAt, time.Second)
+
+	ipStats, err := testQueries.GetClientIPLoginFailures(context.Background(), GetClientIPLoginFailuresParams{
+		ClientIp: clientIP,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), ipStats.Failures)
+
+	// failures before the window do not count
+	stats, err = testQueries.GetUsernameLoginFailures(context.Background(), GetUsernameLoginFailuresParams{
+		Username: username,
+		Since:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.Failures)
+}
+
+func TestRecordLoginFailureTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	locks := 0
+	arg := RecordLoginFailureTxParams{
+		CreateLoginFailureParams: CreateLoginFailureParams{
+			Username: user.Username,
+			ClientIp: "127.0.0.1",
+		},
+		Since:       time.Now().Add(-time.Minute),
+		MaxFailures: 3,
+		AfterLock: func(q Querier) error {
+			locks++
+			return nil
+		},
+	}
+
+	for i := 1; i <= 4; i++ {
+		result, err := store.RecordLoginFailureTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, int64(i), result.Failures)
+		require.Equal(t, i >= 3, result.Locked)
+	}
+	// only the failure that locked the user ran AfterLock
+	require.Equal(t, 1, locks)
+}
+
+func TestUnlockAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateLoginFailure(context.Background(), CreateLoginFailureParams{
+		Username: user.Username,
+		ClientIp: "127.0.0.1",
+	})
+	require.NoError(t, err)
+
+	unlockAccount, err := testQueries.CreateUnlockAccount(context.Background(), CreateUnlockAccountParams{
+		Username:   user.Username,
+		SecretCode: util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	arg := UnlockAccountTxParams{
+		UnlockId:   unlockAccount.ID,
+		SecretCode: unlockAccount.SecretCode,
+	}
+	result, err := store.UnlockAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.UnlockAccount.IsUsed)
+
+	stats, err := testQueries.GetUsernameLoginFailures(context.Background(), GetUsernameLoginFailuresParams{
+		Username: user.Username,
+		Since:    time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.Failures)
+
+	// the link can only be used once
+	_, err = store.UnlockAccountTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
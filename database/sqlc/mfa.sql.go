@@ -0,0 +1,171 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: mfa.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createMfaChallenge = `-- name: CreateMfaChallenge :one
+INSERT INTO mfa_challenges (
+  id,
+  username,
+  expires_at
+) VALUES (
+  $1, $2, $3
+) RETURNING id, username, attempts, is_used, expires_at, created_at
+`
+
+type CreateMfaChallengeParams struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateMfaChallenge(ctx context.Context, arg CreateMfaChallengeParams) (MfaChallenge, error) {
+	row := q.db.QueryRowContext(ctx, createMfaChallenge, arg.ID, arg.Username, arg.ExpiresAt)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createMfaRecoveryCode = `-- name: CreateMfaRecoveryCode :one
+INSERT INTO mfa_recovery_codes (
+  username,
+  hashed_code
+) VALUES (
+  $1, $2
+) RETURNING id, username, hashed_code, used_at, created_at
+`
+
+type CreateMfaRecoveryCodeParams struct {
+	Username   string `json:"username"`
+	HashedCode string `json:"hashed_code"`
+}
+
+func (q *Queries) CreateMfaRecoveryCode(ctx context.Context, arg CreateMfaRecoveryCodeParams) (MfaRecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, createMfaRecoveryCode, arg.Username, arg.HashedCode)
+	var i MfaRecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedCode,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteMfaRecoveryCodes = `-- name: DeleteMfaRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE username = $1
+`
+
+func (q *Queries) DeleteMfaRecoveryCodes(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, deleteMfaRecoveryCodes, username)
+	return err
+}
+
+const failMfaChallenge = `-- name: FailMfaChallenge :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING id, username, attempts, is_used, expires_at, created_at
+`
+
+func (q *Queries) FailMfaChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error) {
+	row := q.db.QueryRowContext(ctx, failMfaChallenge, id)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMfaChallenge = `-- name: GetMfaChallenge :one
+SELECT id, username, attempts, is_used, expires_at, created_at FROM mfa_challenges
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetMfaChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error) {
+	row := q.db.QueryRowContext(ctx, getMfaChallenge, id)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useMfaChallenge = `-- name: UseMfaChallenge :one
+UPDATE mfa_challenges
+SET is_used = true
+WHERE
+  id = $1 AND
+  is_used = false
+RETURNING id, username, attempts, is_used, expires_at, created_at
+`
+
+func (q *Queries) UseMfaChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error) {
+	row := q.db.QueryRowContext(ctx, useMfaChallenge, id)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useMfaRecoveryCode = `-- name: UseMfaRecoveryCode :one
+UPDATE mfa_recovery_codes
+SET used_at = now()
+WHERE
+  username = $1 AND
+  hashed_code = $2 AND
+  used_at IS NULL
+RETURNING id, username, hashed_code, used_at, created_at
+`
+
+type UseMfaRecoveryCodeParams struct {
+	Username   string `json:"username"`
+	HashedCode string `json:"hashed_code"`
+}
+
+func (q *Queries) UseMfaRecoveryCode(ctx context.Context, arg UseMfaRecoveryCodeParams) (MfaRecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, useMfaRecoveryCode, arg.Username, arg.HashedCode)
+	var i MfaRecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedCode,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
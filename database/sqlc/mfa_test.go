@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableMfaTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	// Nothing to enable before a secret is enrolled
+	_, err := store.EnableMfaTx(context.Background(), EnableMfaTxParams{
+		EnableUserMfaParams: EnableUserMfaParams{Username: user.Username},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	secret := sql.NullString{String: util.RandomString(32), Valid: true}
+	enrolled, err := store.SetUserTotpSecret(context.Background(), SetUserTotpSecretParams{
+		TotpSecret: secret,
+		Username:   user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, secret, enrolled.TotpSecret)
+	require.False(t, enrolled.IsMfaEnabled)
+
+	recoveryCode := util.RandomString(10)
+	result, err := store.EnableMfaTx(context.Background(), EnableMfaTxParams{
+		EnableUserMfaParams: EnableUserMfaParams{
+			Username:         user.Username,
+			TotpLastUsedStep: 100,
+		},
+		HashedRecoveryCodes: []string{util.HashRecoveryCode(recoveryCode), util.RandomString(64)},
+	})
+	require.NoError(t, err)
+	require.True(t, result.User.IsMfaEnabled)
+	require.Equal(t, int64(100), result.User.TotpLastUsedStep)
+
+	// The secret can't be replaced once enabled
+	_, err = store.SetUserTotpSecret(context.Background(), SetUserTotpSecretParams{
+		TotpSecret: sql.NullString{String: util.RandomString(32), Valid: true},
+		Username:   user.Username,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg := UseMfaRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: util.HashRecoveryCode(recoveryCode),
+	}
+	used, err := testQueries.UseMfaRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, used.UsedAt.Valid)
+
+	// Each recovery code works once
+	_, err = testQueries.UseMfaRecoveryCode(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdateUserTotpStep(t *testing.T) {
+	user := createRandomUser(t)
+
+	rows, err := testQueries.UpdateUserTotpStep(context.Background(), UpdateUserTotpStepParams{
+		TotpLastUsedStep: 10,
+		Username:         user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// A step is only accepted once, and never one before it
+	for _, step := range []int64{10, 9} {
+		rows, err = testQueries.UpdateUserTotpStep(context.Background(), UpdateUserTotpStepParams{
+			TotpLastUsedStep: step,
+			Username:         user.Username,
+		})
+		require.NoError(t, err)
+		require.Zero(t, rows)
+	}
+}
+
+func TestMfaChallenge(t *testing.T) {
+	user := createRandomUser(t)
+
+	challenge, err := testQueries.CreateMfaChallenge(context.Background(), CreateMfaChallengeParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, challenge.Attempts)
+	require.False(t, challenge.IsUsed)
+
+	failed, err := testQueries.FailMfaChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failed.Attempts)
+
+	used, err := testQueries.UseMfaChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.True(t, used.IsUsed)
+
+	// A challenge is exchanged once
+	_, err = testQueries.UseMfaChallenge(context.Background(), challenge.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := testQueries.GetMfaChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsUsed)
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +18,33 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// active, frozen (sends no money) or closed (for good)
+	Status       string         `json:"status"`
+	ClosedAt     sql.NullTime   `json:"closed_at"`
+	FrozenReason sql.NullString `json:"frozen_reason"`
+	// a frozen account never sends money, and receives none either when set
+	FreezeBlocksIncoming bool `json:"freeze_blocks_incoming"`
+}
+
+type AccountStatusChange struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedBy  string    `json:"changed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code string `json:"code"`
+	// number of digits after the decimal separator, amounts are stored in minor units
+	Exponent  int32     `json:"exponent"`
+	IsEnabled bool      `json:"is_enabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -24,6 +53,69 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer that wrote the entry
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type FxRate struct {
+	ID    int64  `json:"id"`
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+	// how many units of quote one unit of base buys
+	Rate      string    `json:"rate"`
+	ValidFrom time.Time `json:"valid_from"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username       string          `json:"username"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	RequestHash    string          `json:"request_hash"`
+	Response       json.RawMessage `json:"response"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type LoginFailure struct {
+	ID int64 `json:"id"`
+	// as typed by the client, so unknown usernames are throttled like real ones
+	Username  string    `json:"username"`
+	ClientIp  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MfaChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Attempts  int32     `json:"attempts"`
+	IsUsed    bool      `json:"is_used"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID         int64        `json:"id"`
+	Username   string       `json:"username"`
+	HashedCode string       `json:"hashed_code"`
+	UsedAt     sql.NullTime `json:"used_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type PasswordResetRequest struct {
+	ID int64 `json:"id"`
+	// as typed by the client, so unknown emails are throttled like registered ones
+	Email     string    `json:"email"`
+	ClientIp  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ResetPassword struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	SecretCode string    `json:"secret_code"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
 }
 
 type Session struct {
@@ -35,14 +127,47 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+	// id of the login session every rotated refresh token descends from
+	FamilyID uuid.UUID `json:"family_id"`
+	// the refresh token was exchanged for a new one and must not be used again
+	IsRotated bool `json:"is_rotated"`
+}
+
+type Task struct {
+	ID      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	// pending, completed or dead
+	Status      string         `json:"status"`
+	Attempts    int32          `json:"attempts"`
+	MaxAttempts int32          `json:"max_attempts"`
+	LastError   sql.NullString `json:"last_error"`
+	// when the task becomes visible to the processor again
+	RunAt     time.Time `json:"run_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive, in the currency of the source account
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// amount credited, in the currency of the destination account
+	ToAmount int64 `json:"to_amount"`
+	// rate applied to convert amount into to_amount, 1 for same-currency transfers
+	FxRate string `json:"fx_rate"`
+}
+
+type UnlockAccount struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	SecretCode string    `json:"secret_code"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
 }
 
 type User struct {
@@ -54,6 +179,13 @@ type User struct {
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 	IsEmailVerified   bool      `json:"is_email_verified"`
+	// depositor, banker (support staff) or admin
+	Role string `json:"role"`
+	// encrypted with MFA_ENCRYPTION_KEY, set on enrollment and active once is_mfa_enabled
+	TotpSecret   sql.NullString `json:"totp_secret"`
+	IsMfaEnabled bool           `json:"is_mfa_enabled"`
+	// time step of the last accepted code, so a code cannot be replayed
+	TotpLastUsedStep int64 `json:"totp_last_used_step"`
 }
 
 type VerifyEmail struct {
@@ -72,6 +204,15 @@ type LoginUserRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+type VerifyLoginMfaRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type ConfirmTotpRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
 	Password string `json:"password" binding:"required,min=6"`
@@ -85,4 +226,41 @@ type UpdateUserRequest struct {
 	PasswordChangedAt *time.Time `json:"password_changed_at"`
 	FullName          *string    `json:"full_name"`
 	Email             *string    `json:"email,omitempty" binding:"omitempty,email"`
+	KeepSessionID     *string    `json:"keep_session_id" binding:"omitempty,uuid"`
+}
+
+type VerifyEmailRequest struct {
+	EmailId    int64  `form:"email_id" binding:"required"`
+	SecretCode string `form:"secret_code" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	ResetId    int64  `json:"reset_id" binding:"required"`
+	SecretCode string `json:"secret_code" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+}
+
+type UnlockAccountRequest struct {
+	UnlockId   int64  `form:"unlock_id" binding:"required"`
+	SecretCode string `form:"secret_code" binding:"required"`
+}
+
+type CreateCurrencyRequest struct {
+	Code      string `json:"code" binding:"required"`
+	Exponent  int32  `json:"exponent"`
+	IsEnabled *bool  `json:"is_enabled"`
+}
+
+type UpdateCurrencyRequest struct {
+	Code      string `uri:"code" binding:"required"`
+	IsEnabled *bool  `json:"is_enabled"`
+}
+
+type UpdateUserRoleRequest struct {
+	ID   int64  `uri:"id" binding:"required,min=1"`
+	Role string `json:"role"`
 }
@@ -0,0 +1,91 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: password_reset_request.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createPasswordResetRequest = `-- name: CreatePasswordResetRequest :one
+INSERT INTO password_reset_requests (
+  email,
+  client_ip
+) VALUES (
+  $1, $2
+) RETURNING id, email, client_ip, created_at
+`
+
+type CreatePasswordResetRequestParams struct {
+	Email    string `json:"email"`
+	ClientIp string `json:"client_ip"`
+}
+
+func (q *Queries) CreatePasswordResetRequest(ctx context.Context, arg CreatePasswordResetRequestParams) (PasswordResetRequest, error) {
+	row := q.db.QueryRowContext(ctx, createPasswordResetRequest, arg.Email, arg.ClientIp)
+	var i PasswordResetRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.ClientIp,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getClientIPPasswordResetRequests = `-- name: GetClientIPPasswordResetRequests :one
+SELECT
+  count(*) AS requests,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_requested_at
+FROM password_reset_requests
+WHERE
+  client_ip = $1 AND
+  created_at > $2
+`
+
+type GetClientIPPasswordResetRequestsParams struct {
+	ClientIp string    `json:"client_ip"`
+	Since    time.Time `json:"since"`
+}
+
+type GetClientIPPasswordResetRequestsRow struct {
+	Requests        int64     `json:"requests"`
+	LastRequestedAt time.Time `json:"last_requested_at"`
+}
+
+func (q *Queries) GetClientIPPasswordResetRequests(ctx context.Context, arg GetClientIPPasswordResetRequestsParams) (GetClientIPPasswordResetRequestsRow, error) {
+	row := q.db.QueryRowContext(ctx, getClientIPPasswordResetRequests, arg.ClientIp, arg.Since)
+	var i GetClientIPPasswordResetRequestsRow
+	err := row.Scan(&i.Requests, &i.LastRequestedAt)
+	return i, err
+}
+
+const getEmailPasswordResetRequests = `-- name: GetEmailPasswordResetRequests :one
+SELECT
+  count(*) AS requests,
+  COALESCE(max(created_at), 'epoch')::timestamptz AS last_requested_at
+FROM password_reset_requests
+WHERE
+  email = $1 AND
+  created_at > $2
+`
+
+type GetEmailPasswordResetRequestsParams struct {
+	Email string    `json:"email"`
+	Since time.Time `json:"since"`
+}
+
+type GetEmailPasswordResetRequestsRow struct {
+	Requests        int64     `json:"requests"`
+	LastRequestedAt time.Time `json:"last_requested_at"`
+}
+
+func (q *Queries) GetEmailPasswordResetRequests(ctx context.Context, arg GetEmailPasswordResetRequestsParams) (GetEmailPasswordResetRequestsRow, error) {
+	row := q.db.QueryRowContext(ctx, getEmailPasswordResetRequests, arg.Email, arg.Since)
+	var i GetEmailPasswordResetRequestsRow
+	err := row.Scan(&i.Requests, &i.LastRequestedAt)
+	return i, err
+}
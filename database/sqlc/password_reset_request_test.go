@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPasswordResetRequests(t *testing.T) {
+	email := util.RandomEmail()
+	clientIP := util.RandomString(12)
+	since := time.Now().Add(-time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := testQueries.CreatePasswordResetRequest(context.Background(), CreatePasswordResetRequestParams{
+			Email:    email,
+			ClientIp: clientIP,
+		})
+		require.NoError(t, err)
+	}
+
+	stats, err := testQueries.GetEmailPasswordResetRequests(context.Background(), GetEmailPasswordResetRequestsParams{
+		Email: email,
+		Since: since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.Requests)
+	require.WithinDuration(t, time.Now(), stats.LastRequestedAt, time.Second)
+
+	ipStats, err := testQueries.GetClientIPPasswordResetRequests(context.Background(), GetClientIPPasswordResetRequestsParams{
+		ClientIp: clientIP,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), ipStats.Requests)
+
+	// requests before the window do not count
+	stats, err = testQueries.GetEmailPasswordResetRequests(context.Background(), GetEmailPasswordResetRequestsParams{
+		Email: email,
+		Since: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.Requests)
+}
+
+func TestRequestPasswordResetTx(t *testing.T) {
+	store := NewStore(testDB)
+	email := util.RandomEmail()
+
+	arg := RequestPasswordResetTxParams{
+		CreatePasswordResetRequestParams: CreatePasswordResetRequestParams{
+			Email:    email,
+			ClientIp: "127.0.0.1",
+		},
+		AfterCreate: func(q Querier) error {
+			return errors.New("cannot enqueue task")
+		},
+	}
+
+	// the request is rolled back when AfterCreate fails
+	_, err := store.RequestPasswordResetTx(context.Background(), arg)
+	require.Error(t, err)
+
+	arg.AfterCreate = nil
+	result, err := store.RequestPasswordResetTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, email, result.PasswordResetRequest.Email)
+
+	stats, err := testQueries.GetEmailPasswordResetRequests(context.Background(), GetEmailPasswordResetRequestsParams{
+		Email: email,
+		Since: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stats.Requests)
+}
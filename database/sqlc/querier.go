@@ -12,26 +12,87 @@ import (
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSession(ctx context.Context, arg BlockSessionParams) (Session, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	BlockUserSessionsExcept(ctx context.Context, arg BlockUserSessionsExceptParams) (int64, error)
+	ClaimTasks(ctx context.Context, arg ClaimTasksParams) ([]Task, error)
+	CloseAccount(ctx context.Context, id int64) (Account, error)
+	CompleteTask(ctx context.Context, id int64) error
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error)
+	CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFxRate(ctx context.Context, arg CreateFxRateParams) error
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateLoginFailure(ctx context.Context, arg CreateLoginFailureParams) (LoginFailure, error)
+	CreateMfaChallenge(ctx context.Context, arg CreateMfaChallengeParams) (MfaChallenge, error)
+	CreateMfaRecoveryCode(ctx context.Context, arg CreateMfaRecoveryCodeParams) (MfaRecoveryCode, error)
+	CreatePasswordResetRequest(ctx context.Context, arg CreatePasswordResetRequestParams) (PasswordResetRequest, error)
+	CreateResetPassword(ctx context.Context, arg CreateResetPasswordParams) (ResetPassword, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateUnlockAccount(ctx context.Context, arg CreateUnlockAccountParams) (UnlockAccount, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	DeadLetterTask(ctx context.Context, arg DeadLetterTaskParams) error
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteLoginFailures(ctx context.Context, username string) error
+	DeleteMfaRecoveryCodes(ctx context.Context, username string) error
+	EnableUserMfa(ctx context.Context, arg EnableUserMfaParams) (User, error)
+	FailMfaChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetClientIPLoginFailures(ctx context.Context, arg GetClientIPLoginFailuresParams) (GetClientIPLoginFailuresRow, error)
+	GetClientIPPasswordResetRequests(ctx context.Context, arg GetClientIPPasswordResetRequestsParams) (GetClientIPPasswordResetRequestsRow, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
+	GetEmailPasswordResetRequests(ctx context.Context, arg GetEmailPasswordResetRequestsParams) (GetEmailPasswordResetRequestsRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLatestFxRate(ctx context.Context, arg GetLatestFxRateParams) (FxRate, error)
+	GetMfaChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetTask(ctx context.Context, id int64) (Task, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetUserAuthorization(ctx context.Context, username string) (GetUserAuthorizationRow, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id int64) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUsernameLoginFailures(ctx context.Context, arg GetUsernameLoginFailuresParams) (GetUsernameLoginFailuresRow, error)
+	ListAccountStatusChanges(ctx context.Context, accountID int64) ([]AccountStatusChange, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListActiveSessions(ctx context.Context, username string) ([]Session, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
-	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error)
+	ListEntriesInPeriod(ctx context.Context, arg ListEntriesInPeriodParams) ([]Entry, error)
+	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]ListTransfersRow, error)
+	ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]ListTransfersAfterRow, error)
+	ListTransfersByIDs(ctx context.Context, ids []int64) ([]ListTransfersByIDsRow, error)
+	RetryTask(ctx context.Context, arg RetryTaskParams) error
+	RotateSession(ctx context.Context, id uuid.UUID) (Session, error)
+	// Transfers in and out of an account, oldest first. Each direction is served by
+	// the (from_account_id, created_at, id) and (to_account_id, created_at, id) indexes,
+	// and amounts are compared in the currency of the account.
+	SearchAccountTransfers(ctx context.Context, arg SearchAccountTransfersParams) ([]SearchAccountTransfersRow, error)
+	// Same as SearchAccountTransfers, newest first
+	SearchAccountTransfersDesc(ctx context.Context, arg SearchAccountTransfersDescParams) ([]SearchAccountTransfersDescRow, error)
+	SetUserTotpSecret(ctx context.Context, arg SetUserTotpSecretParams) (User, error)
+	SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateCurrencyEnabled(ctx context.Context, arg UpdateCurrencyEnabledParams) (Currency, error)
+	UpdateResetPassword(ctx context.Context, arg UpdateResetPasswordParams) (ResetPassword, error)
+	UpdateUnlockAccount(ctx context.Context, arg UpdateUnlockAccountParams) (UnlockAccount, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateUserTotpStep(ctx context.Context, arg UpdateUserTotpStepParams) (int64, error)
 	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	UseMfaChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error)
+	UseMfaRecoveryCode(ctx context.Context, arg UseMfaRecoveryCodeParams) (MfaRecoveryCode, error)
 }
 
 var _ Querier = (*Queries)(nil)
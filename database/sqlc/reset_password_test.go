@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+
+	_, err := testQueries.CreateLoginFailure(context.Background(), CreateLoginFailureParams{
+		Username: user.Username,
+		ClientIp: "127.0.0.1",
+	})
+	require.NoError(t, err)
+
+	resetPassword, err := testQueries.CreateResetPassword(context.Background(), CreateResetPasswordParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{
+		ResetId:        resetPassword.ID,
+		SecretCode:     resetPassword.SecretCode,
+		HashedPassword: hashedPassword,
+	}
+	result, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.ResetPassword.IsUsed)
+	require.Equal(t, hashedPassword, result.User.HashedPassword)
+	require.True(t, result.User.PasswordChangedAt.After(user.PasswordChangedAt))
+	require.Equal(t, int64(1), result.BlockedSessions)
+
+	blockedSession, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+
+	stats, err := testQueries.GetUsernameLoginFailures(context.Background(), GetUsernameLoginFailuresParams{
+		Username: user.Username,
+		Since:    time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.Failures)
+
+	// the code can only be used once
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -0,0 +1,29 @@
+package db
+
+import "context"
+
+// SearchTransfersParams contains the filters of a transfer search on an account
+type SearchTransfersParams struct {
+	SearchAccountTransfersParams
+	// NewestFirst lists the transfers by descending creation time.
+	// A cursor then points to the next older transfer.
+	NewestFirst bool
+}
+
+// SearchTransfers lists the transfers of an account in the requested order
+func (store *SQLStore) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]SearchAccountTransfersRow, error) {
+	if !arg.NewestFirst {
+		return store.SearchAccountTransfers(ctx, arg.SearchAccountTransfersParams)
+	}
+
+	rows, err := store.SearchAccountTransfersDesc(ctx, SearchAccountTransfersDescParams(arg.SearchAccountTransfersParams))
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]SearchAccountTransfersRow, len(rows))
+	for i, row := range rows {
+		transfers[i] = SearchAccountTransfersRow(row)
+	}
+	return transfers, nil
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	createAccount := func(balance int64) Account {
+		account, err := store.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  balance,
+			Currency: util.USD,
+		})
+		require.NoError(t, err)
+		return account
+	}
+	account1 := createAccount(100)
+	account2 := createAccount(0)
+
+	transfer := func(from Account, to Account, amount int64) TransferTxResult {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		return result
+	}
+	outgoing := transfer(account1, account2, 30)
+	incoming := transfer(account2, account1, 10)
+
+	statement, err := store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account1.ID,
+		From:      time.Now().Add(-time.Hour),
+		To:        time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, statement.Account.ID)
+	require.Equal(t, int64(100), statement.OpeningBalance)
+	require.Equal(t, int64(80), statement.ClosingBalance)
+	require.Len(t, statement.Lines, 2)
+
+	line := statement.Lines[0]
+	require.Equal(t, outgoing.FromEntry.ID, line.Entry.ID)
+	require.Equal(t, int64(70), line.Balance)
+	require.Equal(t, account2.ID, line.CounterpartyAccountID)
+	require.NotNil(t, line.Transfer)
+	require.Equal(t, outgoing.Transfer.ID, line.Transfer.Transfer.ID)
+	require.Equal(t, util.USD, line.Transfer.FromCurrency)
+
+	line = statement.Lines[1]
+	require.Equal(t, incoming.ToEntry.ID, line.Entry.ID)
+	require.Equal(t, int64(80), line.Balance)
+	require.Equal(t, account2.ID, line.CounterpartyAccountID)
+	require.Equal(t, incoming.Transfer.ID, line.Transfer.Transfer.ID)
+
+	// a period before the transfers only has the original balance
+	statement, err = store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account1.ID,
+		From:      time.Now().Add(-2 * time.Hour),
+		To:        time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), statement.OpeningBalance)
+	require.Equal(t, int64(100), statement.ClosingBalance)
+	require.Empty(t, statement.Lines)
+
+	// and a period after them the current one
+	statement, err = store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account1.ID,
+		From:      time.Now().Add(time.Hour),
+		To:        time.Now().Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(80), statement.OpeningBalance)
+	require.Equal(t, int64(80), statement.ClosingBalance)
+	require.Empty(t, statement.Lines)
+}
@@ -2,8 +2,11 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"math"
 	"testing"
 
+	"github.com/forabbie/vank-app/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,6 +18,7 @@ func TestTransferTx(t *testing.T) {
 
 	n := 5
 	amount := int64(10)
+	account1 = fundAccount(t, account1, int64(n)*amount)
 
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
@@ -46,6 +50,8 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, account1.ID, transfer.FromAccountID)
 		require.Equal(t, account2.ID, transfer.ToAccountID)
 		require.Equal(t, amount, transfer.Amount)
+		require.Equal(t, amount, transfer.ToAmount)
+		require.Equal(t, "1", transfer.FxRate)
 		require.NotZero(t, transfer.ID)
 		require.NotZero(t, transfer.CreatedAt)
 
@@ -57,6 +63,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry.ID)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -67,6 +74,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry.ID)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -118,6 +126,8 @@ func TestTransferTxDeadlock(t *testing.T) {
 
 	n := 10
 	amount := int64(10)
+	account1 = fundAccount(t, account1, int64(n)*amount)
+	account2 = fundAccount(t, account2, int64(n)*amount)
 	errs := make(chan error)
 
 	// run n concurrent transfer transactions
@@ -158,3 +168,196 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Empty(t, result)
+
+	// nothing should have been written
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	overdraftLimit := int64(100)
+	account1, err := store.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: overdraftLimit,
+	})
+	require.NoError(t, err)
+
+	// the whole overdraft can be used
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + overdraftLimit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -overdraftLimit, result.FromAccount.Balance)
+
+	// but not a cent more
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxAmountOutOfRange(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// take the account below zero first
+	account1, err := store.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 50,
+	})
+	require.NoError(t, err)
+
+	// a huge amount would overflow the balance arithmetic
+	for _, amount := range []int64{0, -1, MaxTransferAmount + 1, math.MaxInt64} {
+		_, err = store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		require.ErrorIs(t, err, ErrInvalidTransferAmount)
+	}
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        MaxTransferAmount,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	amount := int64(10)
+	account1 = fundAccount(t, account1, 2*amount)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Idempotency: &IdempotencyParams{
+			Username:    account1.Owner,
+			Key:         util.RandomString(16),
+			RequestHash: util.RandomString(64),
+		},
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	record, err := store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       arg.Idempotency.Username,
+		IdempotencyKey: arg.Idempotency.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, arg.Idempotency.RequestHash, record.RequestHash)
+
+	var recorded TransferTxResult
+	err = json.Unmarshal(record.Response, &recorded)
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, recorded.Transfer.ID)
+
+	// the same key cannot move money twice
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyExists)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updatedAccount1.Balance)
+}
+
+func TestFxTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	usdAccount, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  1000,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	eurAccount, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: util.EUR,
+	})
+	require.NoError(t, err)
+
+	result, err := store.FxTransferTx(context.Background(), FxTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: usdAccount.ID,
+			ToAccountID:   eurAccount.ID,
+			Amount:        1000,
+		},
+		ToAmount: 920,
+		FxRate:   "0.92",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1000), result.Transfer.Amount)
+	require.Equal(t, int64(920), result.Transfer.ToAmount)
+	require.Equal(t, "0.92", result.Transfer.FxRate)
+
+	// each entry is in the currency of its own account
+	require.Equal(t, int64(-1000), result.FromEntry.Amount)
+	require.Equal(t, int64(920), result.ToEntry.Amount)
+
+	require.Equal(t, int64(0), result.FromAccount.Balance)
+	require.Equal(t, int64(920), result.ToAccount.Balance)
+
+	// the source currency amount is still checked against the balance
+	_, err = store.FxTransferTx(context.Background(), FxTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: usdAccount.ID,
+			ToAccountID:   eurAccount.ID,
+			Amount:        1,
+		},
+		ToAmount: 1,
+		FxRate:   "0.92",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+	return account
+}
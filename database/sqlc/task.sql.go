@@ -0,0 +1,184 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: task.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const claimTasks = `-- name: ClaimTasks :many
+UPDATE tasks
+SET
+  attempts = attempts + 1,
+  run_at = $1,
+  updated_at = now()
+WHERE id IN (
+  SELECT id FROM tasks
+  WHERE status = 'pending' AND run_at <= now()
+  ORDER BY run_at
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, type, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at
+`
+
+type ClaimTasksParams struct {
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+	BatchSize      int32     `json:"batch_size"`
+}
+
+func (q *Queries) ClaimTasks(ctx context.Context, arg ClaimTasksParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, claimTasks, arg.LeaseExpiresAt, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.LastError,
+			&i.RunAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeTask = `-- name: CompleteTask :exec
+UPDATE tasks
+SET
+  status = 'completed',
+  updated_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) CompleteTask(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, completeTask, id)
+	return err
+}
+
+const createTask = `-- name: CreateTask :one
+INSERT INTO tasks (
+  type,
+  payload,
+  max_attempts,
+  run_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, type, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at
+`
+
+type CreateTaskParams struct {
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	MaxAttempts int32           `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+}
+
+func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, createTask,
+		arg.Type,
+		arg.Payload,
+		arg.MaxAttempts,
+		arg.RunAt,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.LastError,
+		&i.RunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deadLetterTask = `-- name: DeadLetterTask :exec
+UPDATE tasks
+SET
+  status = 'dead',
+  last_error = $1,
+  updated_at = now()
+WHERE id = $2
+`
+
+type DeadLetterTaskParams struct {
+	LastError sql.NullString `json:"last_error"`
+	ID        int64          `json:"id"`
+}
+
+func (q *Queries) DeadLetterTask(ctx context.Context, arg DeadLetterTaskParams) error {
+	_, err := q.db.ExecContext(ctx, deadLetterTask, arg.LastError, arg.ID)
+	return err
+}
+
+const getTask = `-- name: GetTask :one
+SELECT id, type, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at FROM tasks
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTask(ctx context.Context, id int64) (Task, error) {
+	row := q.db.QueryRowContext(ctx, getTask, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.LastError,
+		&i.RunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const retryTask = `-- name: RetryTask :exec
+UPDATE tasks
+SET
+  run_at = $1,
+  last_error = $2,
+  updated_at = now()
+WHERE id = $3
+`
+
+type RetryTaskParams struct {
+	RunAt     time.Time      `json:"run_at"`
+	LastError sql.NullString `json:"last_error"`
+	ID        int64          `json:"id"`
+}
+
+func (q *Queries) RetryTask(ctx context.Context, arg RetryTaskParams) error {
+	_, err := q.db.ExecContext(ctx, retryTask, arg.RunAt, arg.LastError, arg.ID)
+	return err
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTask(t *testing.T) Task {
+	arg := CreateTaskParams{
+		Type:        "task:" + util.RandomString(6),
+		Payload:     json.RawMessage(`{"username":"` + util.RandomOwner() + `"}`),
+		MaxAttempts: 3,
+		RunAt:       time.Now().Add(-time.Second),
+	}
+	task, err := testQueries.CreateTask(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Type, task.Type)
+	require.JSONEq(t, string(arg.Payload), string(task.Payload))
+	require.Equal(t, "pending", task.Status)
+	require.Zero(t, task.Attempts)
+	require.Equal(t, arg.MaxAttempts, task.MaxAttempts)
+	require.False(t, task.LastError.Valid)
+
+	return task
+}
+
+func findTask(tasks []Task, id int64) (Task, bool) {
+	for _, task := range tasks {
+		if task.ID == id {
+			return task, true
+		}
+	}
+	return Task{}, false
+}
+
+func TestClaimTasks(t *testing.T) {
+	task := createRandomTask(t)
+
+	arg := ClaimTasksParams{
+		LeaseExpiresAt: time.Now().Add(time.Minute),
+		BatchSize:      1000,
+	}
+	tasks, err := testQueries.ClaimTasks(context.Background(), arg)
+	require.NoError(t, err)
+
+	claimed, ok := findTask(tasks, task.ID)
+	require.True(t, ok)
+	require.Equal(t, int32(1), claimed.Attempts)
+	require.WithinDuration(t, arg.LeaseExpiresAt, claimed.RunAt, time.Second)
+
+	// a leased task is not handed out twice
+	tasks, err = testQueries.ClaimTasks(context.Background(), arg)
+	require.NoError(t, err)
+	_, ok = findTask(tasks, task.ID)
+	require.False(t, ok)
+}
+
+func TestRetryTask(t *testing.T) {
+	task := createRandomTask(t)
+
+	arg := RetryTaskParams{
+		ID:        task.ID,
+		RunAt:     time.Now().Add(time.Hour),
+		LastError: sql.NullString{String: util.RandomString(10), Valid: true},
+	}
+	err := testQueries.RetryTask(context.Background(), arg)
+	require.NoError(t, err)
+
+	retried, err := testQueries.GetTask(context.Background(), task.ID)
+	require.NoError(t, err)
+	require.Equal(t, "pending", retried.Status)
+	require.Equal(t, arg.LastError, retried.LastError)
+	require.WithinDuration(t, arg.RunAt, retried.RunAt, time.Second)
+}
+
+func TestCompleteAndDeadLetterTask(t *testing.T) {
+	task1 := createRandomTask(t)
+	task2 := createRandomTask(t)
+
+	err := testQueries.CompleteTask(context.Background(), task1.ID)
+	require.NoError(t, err)
+
+	err = testQueries.DeadLetterTask(context.Background(), DeadLetterTaskParams{
+		ID:        task2.ID,
+		LastError: sql.NullString{String: util.RandomString(10), Valid: true},
+	})
+	require.NoError(t, err)
+
+	completed, err := testQueries.GetTask(context.Background(), task1.ID)
+	require.NoError(t, err)
+	require.Equal(t, "completed", completed.Status)
+
+	dead, err := testQueries.GetTask(context.Background(), task2.ID)
+	require.NoError(t, err)
+	require.Equal(t, "dead", dead.Status)
+	require.True(t, dead.LastError.Valid)
+}
@@ -7,26 +7,40 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  fx_rate
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	ToAmount      int64  `json:"to_amount"`
+	FxRate        string `json:"fx_rate"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.FxRate,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +48,14 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,47 +68,377 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
-  from_account_id = $1 OR 
-  to_account_id = $2
-ORDER BY id
-LIMIT $3
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.fx_rate, from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (transfers.from_account_id = $1 OR transfers.to_account_id = $2)
+  -- unless the viewer may read any account, each row must involve one of their accounts
+  AND ($3::varchar IS NULL OR from_account.owner = $3 OR to_account.owner = $3)
+ORDER BY transfers.id
+LIMIT $5
 OFFSET $4
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Viewer        sql.NullString `json:"viewer"`
+	Offset        int32          `json:"offset"`
+	Limit         int32          `json:"limit"`
 }
 
-func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+type ListTransfersRow struct {
+	Transfer     Transfer `json:"transfer"`
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+}
+
+func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]ListTransfersRow, error) {
 	rows, err := q.db.QueryContext(ctx, listTransfers,
 		arg.FromAccountID,
 		arg.ToAccountID,
-		arg.Limit,
+		arg.Viewer,
 		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersRow{}
+	for rows.Next() {
+		var i ListTransfersRow
+		if err := rows.Scan(
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.ToAmount,
+			&i.Transfer.FxRate,
+			&i.FromCurrency,
+			&i.ToCurrency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAfter = `-- name: ListTransfersAfter :many
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.fx_rate, from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (transfers.from_account_id = $1 OR transfers.to_account_id = $2)
+  AND ($3::varchar IS NULL OR from_account.owner = $3 OR to_account.owner = $3)
+  AND (transfers.created_at, transfers.id) > ($4::timestamptz, $5::bigint)
+ORDER BY transfers.created_at, transfers.id
+LIMIT $6
+`
+
+type ListTransfersAfterParams struct {
+	FromAccountID  int64          `json:"from_account_id"`
+	ToAccountID    int64          `json:"to_account_id"`
+	Viewer         sql.NullString `json:"viewer"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        int64          `json:"after_id"`
+	PageSize       int32          `json:"page_size"`
+}
+
+type ListTransfersAfterRow struct {
+	Transfer     Transfer `json:"transfer"`
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+}
+
+func (q *Queries) ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]ListTransfersAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersAfter,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Viewer,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersAfterRow{}
+	for rows.Next() {
+		var i ListTransfersAfterRow
+		if err := rows.Scan(
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.ToAmount,
+			&i.Transfer.FxRate,
+			&i.FromCurrency,
+			&i.ToCurrency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersByIDs = `-- name: ListTransfersByIDs :many
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.fx_rate, from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE transfers.id = ANY($1::bigint[])
+ORDER BY transfers.id
+`
+
+type ListTransfersByIDsRow struct {
+	Transfer     Transfer `json:"transfer"`
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+}
+
+func (q *Queries) ListTransfersByIDs(ctx context.Context, ids []int64) ([]ListTransfersByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersByIDsRow{}
+	for rows.Next() {
+		var i ListTransfersByIDsRow
+		if err := rows.Scan(
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.ToAmount,
+			&i.Transfer.FxRate,
+			&i.FromCurrency,
+			&i.ToCurrency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAccountTransfers = `-- name: SearchAccountTransfers :many
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.fx_rate, from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (
+    (
+      $1::boolean
+      AND transfers.from_account_id = $2
+      AND ($3::bigint IS NULL OR transfers.to_account_id = $3)
+      AND ($4::bigint IS NULL OR transfers.amount >= $4)
+      AND ($5::bigint IS NULL OR transfers.amount <= $5)
+    ) OR (
+      $6::boolean
+      AND transfers.to_account_id = $2
+      AND ($3::bigint IS NULL OR transfers.from_account_id = $3)
+      AND ($4::bigint IS NULL OR transfers.to_amount >= $4)
+      AND ($5::bigint IS NULL OR transfers.to_amount <= $5)
+    )
+  )
+  AND ($7::timestamptz IS NULL OR transfers.created_at >= $7)
+  AND ($8::timestamptz IS NULL OR transfers.created_at < $8)
+  AND (
+    $9::timestamptz IS NULL OR
+    (transfers.created_at, transfers.id) > ($9, $10::bigint)
+  )
+ORDER BY transfers.created_at, transfers.id
+LIMIT $11
+`
+
+type SearchAccountTransfersParams struct {
+	Outgoing       bool          `json:"outgoing"`
+	AccountID      int64         `json:"account_id"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Incoming       bool          `json:"incoming"`
+	FromTime       sql.NullTime  `json:"from_time"`
+	ToTime         sql.NullTime  `json:"to_time"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+	PageSize       int32         `json:"page_size"`
+}
+
+type SearchAccountTransfersRow struct {
+	Transfer     Transfer `json:"transfer"`
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+}
+
+// Transfers in and out of an account, oldest first. Each direction is served by
+// the (from_account_id, created_at, id) and (to_account_id, created_at, id) indexes,
+// and amounts are compared in the currency of the account.
+func (q *Queries) SearchAccountTransfers(ctx context.Context, arg SearchAccountTransfersParams) ([]SearchAccountTransfersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchAccountTransfers,
+		arg.Outgoing,
+		arg.AccountID,
+		arg.CounterpartyID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Incoming,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchAccountTransfersRow{}
+	for rows.Next() {
+		var i SearchAccountTransfersRow
+		if err := rows.Scan(
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.ToAmount,
+			&i.Transfer.FxRate,
+			&i.FromCurrency,
+			&i.ToCurrency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAccountTransfersDesc = `-- name: SearchAccountTransfersDesc :many
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.fx_rate, from_account.currency AS from_currency, to_account.currency AS to_currency
+FROM transfers
+JOIN accounts AS from_account ON from_account.id = transfers.from_account_id
+JOIN accounts AS to_account ON to_account.id = transfers.to_account_id
+WHERE
+  (
+    (
+      $1::boolean
+      AND transfers.from_account_id = $2
+      AND ($3::bigint IS NULL OR transfers.to_account_id = $3)
+      AND ($4::bigint IS NULL OR transfers.amount >= $4)
+      AND ($5::bigint IS NULL OR transfers.amount <= $5)
+    ) OR (
+      $6::boolean
+      AND transfers.to_account_id = $2
+      AND ($3::bigint IS NULL OR transfers.from_account_id = $3)
+      AND ($4::bigint IS NULL OR transfers.to_amount >= $4)
+      AND ($5::bigint IS NULL OR transfers.to_amount <= $5)
+    )
+  )
+  AND ($7::timestamptz IS NULL OR transfers.created_at >= $7)
+  AND ($8::timestamptz IS NULL OR transfers.created_at < $8)
+  AND (
+    $9::timestamptz IS NULL OR
+    (transfers.created_at, transfers.id) < ($9, $10::bigint)
+  )
+ORDER BY transfers.created_at DESC, transfers.id DESC
+LIMIT $11
+`
+
+type SearchAccountTransfersDescParams struct {
+	Outgoing       bool          `json:"outgoing"`
+	AccountID      int64         `json:"account_id"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Incoming       bool          `json:"incoming"`
+	FromTime       sql.NullTime  `json:"from_time"`
+	ToTime         sql.NullTime  `json:"to_time"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+	PageSize       int32         `json:"page_size"`
+}
+
+type SearchAccountTransfersDescRow struct {
+	Transfer     Transfer `json:"transfer"`
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+}
+
+// Same as SearchAccountTransfers, newest first
+func (q *Queries) SearchAccountTransfersDesc(ctx context.Context, arg SearchAccountTransfersDescParams) ([]SearchAccountTransfersDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchAccountTransfersDesc,
+		arg.Outgoing,
+		arg.AccountID,
+		arg.CounterpartyID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Incoming,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Transfer{}
+	items := []SearchAccountTransfersDescRow{}
 	for rows.Next() {
-		var i Transfer
+		var i SearchAccountTransfersDescRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.FromAccountID,
-			&i.ToAccountID,
-			&i.Amount,
-			&i.CreatedAt,
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.ToAmount,
+			&i.Transfer.FxRate,
+			&i.FromCurrency,
+			&i.ToCurrency,
 		); err != nil {
 			return nil, err
 		}
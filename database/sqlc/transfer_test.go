@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -18,6 +19,8 @@ func TestCreateTransfer(t *testing.T) {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        10,
+		ToAmount:      10,
+		FxRate:        "1",
 	}
 
 	// Insert transfer into DB
@@ -29,6 +32,8 @@ func TestCreateTransfer(t *testing.T) {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, arg.FxRate, transfer.FxRate)
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
 }
@@ -38,10 +43,13 @@ func createRandomTransfer(t *testing.T) Transfer {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
 
+	amount := util.RandomMoney()
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		ToAmount:      amount,
+		FxRate:        "1",
 	}
 
 	// Insert transfer into DB
@@ -88,10 +96,107 @@ func TestListTransfers(t *testing.T) {
 	// Validate data
 	require.Len(t, transfers, 1)
 
-	transfer2 := transfers[0]
+	fromAccount, err := testQueries.GetAccount(context.Background(), transfer1.FromAccountID)
+	require.NoError(t, err)
+	toAccount, err := testQueries.GetAccount(context.Background(), transfer1.ToAccountID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Currency, transfers[0].FromCurrency)
+	require.Equal(t, toAccount.Currency, transfers[0].ToCurrency)
+
+	transfer2 := transfers[0].Transfer
 	require.Equal(t, transfer1.ID, transfer2.ID)
 	require.Equal(t, transfer1.FromAccountID, transfer2.FromAccountID)
 	require.Equal(t, transfer1.ToAccountID, transfer2.ToAccountID)
 	require.Equal(t, transfer1.Amount, transfer2.Amount)
 	require.WithinDuration(t, transfer1.CreatedAt, transfer2.CreatedAt, time.Second)
 }
+
+func TestListTransfersViewer(t *testing.T) {
+	transfer := createRandomTransfer(t)
+	fromAccount, err := testQueries.GetAccount(context.Background(), transfer.FromAccountID)
+	require.NoError(t, err)
+	stranger := createRandomAccount(t)
+
+	arg := ListTransfersParams{
+		FromAccountID: stranger.ID,
+		ToAccountID:   transfer.ToAccountID,
+		Limit:         5,
+	}
+
+	// the transfer only involves accounts of other users
+	arg.Viewer = sql.NullString{String: stranger.Owner, Valid: true}
+	transfers, err := testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	arg.Viewer = sql.NullString{String: fromAccount.Owner, Valid: true}
+	transfers, err = testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, transfer.ID, transfers[0].Transfer.ID)
+}
+
+func TestSearchTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	other1 := createRandomAccount(t)
+	other2 := createRandomAccount(t)
+
+	createTransfer := func(from, to Account, amount int64) Transfer {
+		transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+			ToAmount:      amount,
+			FxRate:        "1",
+		})
+		require.NoError(t, err)
+		return transfer
+	}
+
+	out1 := createTransfer(account, other1, 100)
+	in1 := createTransfer(other1, account, 200)
+	out2 := createTransfer(account, other2, 300)
+
+	search := func(arg SearchTransfersParams) []int64 {
+		arg.AccountID = account.ID
+		arg.PageSize = 10
+
+		rows, err := store.SearchTransfers(context.Background(), arg)
+		require.NoError(t, err)
+
+		ids := make([]int64, len(rows))
+		for i, row := range rows {
+			ids[i] = row.Transfer.ID
+		}
+		return ids
+	}
+	both := SearchAccountTransfersParams{Outgoing: true, Incoming: true}
+
+	require.Equal(t, []int64{out1.ID, in1.ID, out2.ID}, search(SearchTransfersParams{SearchAccountTransfersParams: both}))
+	require.Equal(t, []int64{out2.ID, in1.ID, out1.ID}, search(SearchTransfersParams{SearchAccountTransfersParams: both, NewestFirst: true}))
+
+	outgoing := SearchAccountTransfersParams{Outgoing: true}
+	require.Equal(t, []int64{out1.ID, out2.ID}, search(SearchTransfersParams{SearchAccountTransfersParams: outgoing}))
+
+	// the counterparty is matched on the other side of each direction
+	withOther1 := both
+	withOther1.CounterpartyID = sql.NullInt64{Int64: other1.ID, Valid: true}
+	require.Equal(t, []int64{out1.ID, in1.ID}, search(SearchTransfersParams{SearchAccountTransfersParams: withOther1}))
+
+	amountRange := both
+	amountRange.MinAmount = sql.NullInt64{Int64: 200, Valid: true}
+	amountRange.MaxAmount = sql.NullInt64{Int64: 300, Valid: true}
+	require.Equal(t, []int64{in1.ID, out2.ID}, search(SearchTransfersParams{SearchAccountTransfersParams: amountRange}))
+
+	// the cursor continues after the given transfer, in either order
+	afterIn1 := both
+	afterIn1.AfterCreatedAt = sql.NullTime{Time: in1.CreatedAt, Valid: true}
+	afterIn1.AfterID = sql.NullInt64{Int64: in1.ID, Valid: true}
+	require.Equal(t, []int64{out2.ID}, search(SearchTransfersParams{SearchAccountTransfersParams: afterIn1}))
+	require.Equal(t, []int64{out1.ID}, search(SearchTransfersParams{SearchAccountTransfersParams: afterIn1, NewestFirst: true}))
+
+	future := both
+	future.FromTime = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	require.Empty(t, search(SearchTransfersParams{SearchAccountTransfersParams: future}))
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Different types of error returned by the CloseAccountTx function
+var (
+	// ErrBalanceNotZero is returned when an account still holds money, or owes some, and nothing sweeps it
+	ErrBalanceNotZero = errors.New("account balance is not zero")
+	// ErrInvalidSweepAccount is returned when the remaining balance can't be swept to the given account
+	ErrInvalidSweepAccount = errors.New("sweep account must be another active account of the same owner")
+)
+
+// CloseAccountTxParams contains the input parameters of the close account transaction
+type CloseAccountTxParams struct {
+	AccountID int64
+	// SweepToAccountID receives the remaining balance. When 0, the balance must already be zero.
+	SweepToAccountID int64
+	// Convert quotes the balance in the currency of the sweep account. It runs inside
+	// the transaction with its queries, and is only needed when the two currencies differ.
+	Convert func(q Querier, amount int64, from string, to string) (toAmount int64, fxRate string, err error)
+	// Reason and ChangedBy are recorded in the audit trail
+	Reason    string
+	ChangedBy string
+}
+
+// CloseAccountTxResult is the result of the close account transaction
+type CloseAccountTxResult struct {
+	Account Account
+	// Sweep moved the remaining balance out of the account, if there was any
+	Sweep *TransferTxResult
+}
+
+// CloseAccountTx closes an active account for good and records it in the audit trail. A positive balance is first
+// transferred, converted if need be, to the sweep account in the same transaction; a negative one has to be paid back.
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var account, sweepAccount Account
+		var err error
+
+		if arg.SweepToAccountID != 0 {
+			if arg.SweepToAccountID == arg.AccountID {
+				return ErrInvalidSweepAccount
+			}
+			account, sweepAccount, err = lockAccounts(ctx, q, arg.AccountID, arg.SweepToAccountID)
+		} else {
+			account, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := checkActive(account); err != nil {
+			return err
+		}
+
+		if arg.SweepToAccountID != 0 {
+			if sweepAccount.Owner != account.Owner || checkActive(sweepAccount) != nil {
+				return ErrInvalidSweepAccount
+			}
+		}
+
+		if account.Balance < 0 || (account.Balance > 0 && arg.SweepToAccountID == 0) {
+			return ErrBalanceNotZero
+		}
+
+		if account.Balance > 0 {
+			toAmount, fxRate := account.Balance, "1"
+			if sweepAccount.Currency != account.Currency {
+				if arg.Convert == nil {
+					return ErrInvalidSweepAccount
+				}
+				toAmount, fxRate, err = arg.Convert(q, account.Balance, account.Currency, sweepAccount.Currency)
+				if err != nil {
+					return fmt.Errorf("failed to convert the balance: %w", err)
+				}
+			}
+
+			sweep, err := moveMoney(ctx, q, FxTransferTxParams{
+				TransferTxParams: TransferTxParams{
+					FromAccountID: account.ID,
+					ToAccountID:   sweepAccount.ID,
+					Amount:        account.Balance,
+				},
+				ToAmount: toAmount,
+				FxRate:   fxRate,
+			})
+			if err != nil {
+				return err
+			}
+			result.Sweep = &sweep
+		}
+
+		result.Account, err = q.CloseAccount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  account.ID,
+			FromStatus: account.Status,
+			ToStatus:   AccountStatusClosed,
+			Reason:     arg.Reason,
+			ChangedBy:  arg.ChangedBy,
+		})
+		return err
+	})
+
+	return result, err
+}
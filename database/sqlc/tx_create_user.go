@@ -7,7 +7,9 @@ import (
 
 type CreateUserTxParams struct {
 	CreateUserParams
-	AfterCreate func(user User) error
+	// AfterCreate runs inside the transaction with its queries, so anything it writes
+	// is committed or rolled back together with the new user
+	AfterCreate func(q Querier, user User) error
 }
 
 type CreateUserTxResult struct {
@@ -25,11 +27,9 @@ func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams)
 			return err
 		}
 
-		// return arg.AfterCreate(result.User)
-
 		// Call AfterCreate function (send verification email)
 		if arg.AfterCreate != nil {
-			err = arg.AfterCreate(result.User)
+			err = arg.AfterCreate(q, result.User)
 			if err != nil {
 				return fmt.Errorf("failed to execute AfterCreate: %w", err)
 			}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+)
+
+// EnableMfaTxParams contains the input parameters of the enable MFA transaction
+type EnableMfaTxParams struct {
+	EnableUserMfaParams
+	// HashedRecoveryCodes replace any recovery codes the user had before
+	HashedRecoveryCodes []string
+}
+
+// EnableMfaTxResult is the result of the enable MFA transaction
+type EnableMfaTxResult struct {
+	User User
+}
+
+// EnableMfaTx turns on two-factor authentication with the user's enrolled TOTP secret
+// and stores a fresh set of recovery codes within a database transaction.
+// It returns sql.ErrNoRows if the user has not enrolled a secret.
+func (store *SQLStore) EnableMfaTx(ctx context.Context, arg EnableMfaTxParams) (EnableMfaTxResult, error) {
+	var result EnableMfaTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.EnableUserMfa(ctx, arg.EnableUserMfaParams)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteMfaRecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, hashedCode := range arg.HashedRecoveryCodes {
+			_, err = q.CreateMfaRecoveryCode(ctx, CreateMfaRecoveryCodeParams{
+				Username:   arg.Username,
+				HashedCode: hashedCode,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
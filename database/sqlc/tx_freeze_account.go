@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrAccountNotFrozen is returned when unfreezing an account that isn't frozen
+var ErrAccountNotFrozen = errors.New("account is not frozen")
+
+// FreezeAccountTxParams contains the input parameters of the freeze account transaction
+type FreezeAccountTxParams struct {
+	AccountID int64
+	Reason    string
+	// BlockIncoming stops the account from receiving money as well as sending it
+	BlockIncoming bool
+	// ChangedBy is the username of the staff member freezing the account
+	ChangedBy string
+}
+
+// UnfreezeAccountTxParams contains the input parameters of the unfreeze account transaction
+type UnfreezeAccountTxParams struct {
+	AccountID int64
+	Reason    string
+	ChangedBy string
+}
+
+// AccountStatusTxResult is the result of the freeze and unfreeze account transactions
+type AccountStatusTxResult struct {
+	Account Account
+	Change  AccountStatusChange
+}
+
+// FreezeAccountTx stops an account from sending money, and optionally from receiving it,
+// and records the change in the audit trail. Freezing a frozen account again replaces its reason.
+func (store *SQLStore) FreezeAccountTx(ctx context.Context, arg FreezeAccountTxParams) (AccountStatusTxResult, error) {
+	var result AccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:                   arg.AccountID,
+			Status:               AccountStatusFrozen,
+			FrozenReason:         sql.NullString{String: arg.Reason, Valid: true},
+			FreezeBlocksIncoming: arg.BlockIncoming,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Change, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  arg.AccountID,
+			FromStatus: account.Status,
+			ToStatus:   AccountStatusFrozen,
+			Reason:     arg.Reason,
+			ChangedBy:  arg.ChangedBy,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// UnfreezeAccountTx makes a frozen account active again and records the change in the audit trail
+func (store *SQLStore) UnfreezeAccountTx(ctx context.Context, arg UnfreezeAccountTxParams) (AccountStatusTxResult, error) {
+	var result AccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status != AccountStatusFrozen {
+			return ErrAccountNotFrozen
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     arg.AccountID,
+			Status: AccountStatusActive,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Change, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  arg.AccountID,
+			FromStatus: account.Status,
+			ToStatus:   AccountStatusActive,
+			Reason:     arg.Reason,
+			ChangedBy:  arg.ChangedBy,
+		})
+		return err
+	})
+
+	return result, err
+}
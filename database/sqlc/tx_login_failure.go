@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordLoginFailureTxParams contains the input parameters of the record login failure transaction
+type RecordLoginFailureTxParams struct {
+	CreateLoginFailureParams
+	// Since is the start of the window in which failures count towards a lockout
+	Since time.Time
+	// MaxFailures is the number of failures in the window that locks the username, zero disables the lockout
+	MaxFailures int64
+	// AfterLock runs inside the transaction with its queries when this failure locks the username,
+	// so anything it writes is committed or rolled back together with the failure
+	AfterLock func(q Querier) error
+}
+
+// RecordLoginFailureTxResult is the result of the record login failure transaction
+type RecordLoginFailureTxResult struct {
+	LoginFailure LoginFailure
+	Failures     int64
+	LastFailedAt time.Time
+	Locked       bool
+}
+
+// RecordLoginFailureTx records a failed login and counts the recent failures of the username within a database transaction
+func (store *SQLStore) RecordLoginFailureTx(ctx context.Context, arg RecordLoginFailureTxParams) (RecordLoginFailureTxResult, error) {
+	var result RecordLoginFailureTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.LoginFailure, err = q.CreateLoginFailure(ctx, arg.CreateLoginFailureParams)
+		if err != nil {
+			return err
+		}
+
+		stats, err := q.GetUsernameLoginFailures(ctx, GetUsernameLoginFailuresParams{
+			Username: arg.Username,
+			Since:    arg.Since,
+		})
+		if err != nil {
+			return err
+		}
+		result.Failures = stats.Failures
+		result.LastFailedAt = stats.LastFailedAt
+		result.Locked = arg.MaxFailures > 0 && stats.Failures >= arg.MaxFailures
+
+		// Only the failure that crosses the threshold triggers AfterLock, not every one after it
+		if arg.AfterLock != nil && arg.MaxFailures > 0 && stats.Failures == arg.MaxFailures {
+			err = arg.AfterLock(q)
+			if err != nil {
+				return fmt.Errorf("failed to execute AfterLock: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
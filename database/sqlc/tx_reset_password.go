@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ResetPasswordTxParams contains the input parameters of the reset password transaction
+type ResetPasswordTxParams struct {
+	ResetId        int64
+	SecretCode     string
+	HashedPassword string
+}
+
+// ResetPasswordTxResult is the result of the reset password transaction
+type ResetPasswordTxResult struct {
+	User            User
+	ResetPassword   ResetPassword
+	BlockedSessions int64
+}
+
+// ResetPasswordTx marks a reset password record as used and sets the new password of its user within a database transaction.
+// Like a password change, it stamps password_changed_at and blocks every session of the user,
+// and it clears their failed logins so a locked out user can log in with the new password.
+// It returns sql.ErrNoRows if the secret code is wrong, already used or expired.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.ResetPassword, err = q.UpdateResetPassword(ctx, UpdateResetPasswordParams{
+			ID:         arg.ResetId,
+			SecretCode: arg.SecretCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err := q.GetUserByUsername(ctx, result.ResetPassword.Username)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			ID: user.ID,
+			HashedPassword: sql.NullString{
+				String: arg.HashedPassword,
+				Valid:  true,
+			},
+			PasswordChangedAt: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteLoginFailures(ctx, user.Username)
+	})
+
+	return result, err
+}
+
+// RequestPasswordResetTxParams contains the input parameters of the request password reset transaction
+type RequestPasswordResetTxParams struct {
+	CreatePasswordResetRequestParams
+	// AfterCreate runs inside the transaction with its queries once the request is recorded,
+	// so anything it writes is committed or rolled back together with the request
+	AfterCreate func(q Querier) error
+}
+
+// RequestPasswordResetTxResult is the result of the request password reset transaction
+type RequestPasswordResetTxResult struct {
+	PasswordResetRequest PasswordResetRequest
+}
+
+// RequestPasswordResetTx records a password reset request, whether its email belongs to a user or not,
+// within a database transaction
+func (store *SQLStore) RequestPasswordResetTx(ctx context.Context, arg RequestPasswordResetTxParams) (RequestPasswordResetTxResult, error) {
+	var result RequestPasswordResetTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.PasswordResetRequest, err = q.CreatePasswordResetRequest(ctx, arg.CreatePasswordResetRequestParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterCreate != nil {
+			err = arg.AfterCreate(q)
+			if err != nil {
+				return fmt.Errorf("failed to execute AfterCreate: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RotateSessionTxParams contains the input parameters of the rotate session transaction
+type RotateSessionTxParams struct {
+	OldSessionID uuid.UUID
+	NewSession   CreateSessionParams
+}
+
+// RotateSessionTxResult is the result of the rotate session transaction
+type RotateSessionTxResult struct {
+	OldSession Session
+	NewSession Session
+}
+
+// RotateSessionTx marks a session's refresh token as used and creates the session for its replacement
+// in the same family within a database transaction.
+// It returns sql.ErrNoRows if the old session was already rotated, e.g. by a concurrent renewal.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.OldSession, err = q.RotateSession(ctx, arg.OldSessionID)
+		if err != nil {
+			return err
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = result.OldSession.FamilyID
+		result.NewSession, err = q.CreateSession(ctx, newSession)
+		return err
+	})
+
+	return result, err
+}
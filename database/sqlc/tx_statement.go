@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// StatementTxParams contains the input parameters of the statement transaction
+type StatementTxParams struct {
+	AccountID int64
+	// From is the start of the period, inclusive
+	From time.Time
+	// To is the end of the period, exclusive
+	To time.Time
+}
+
+// StatementLine is an entry of a statement with the balance of the account right after it
+type StatementLine struct {
+	Entry Entry
+	// Transfer is the transfer that wrote the entry, nil for an entry that predates the link
+	Transfer *ListTransfersByIDsRow
+	// CounterpartyAccountID is the other account of the transfer, 0 when there is no transfer
+	CounterpartyAccountID int64
+	Balance               int64
+}
+
+// StatementTxResult is the result of the statement transaction
+type StatementTxResult struct {
+	Account        Account
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []StatementLine
+}
+
+// StatementTx reads the entries of an account over a period with its opening, running and closing balances.
+// Balances are derived backwards from the current balance, so they also hold for accounts
+// created with a balance. Everything is read from one snapshot, so that concurrent
+// transfers cannot make the balances disagree with the entries.
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error) {
+	var result StatementTxResult
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		sinceFrom, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.From,
+		})
+		if err != nil {
+			return err
+		}
+
+		sinceTo, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.OpeningBalance = result.Account.Balance - sinceFrom
+		result.ClosingBalance = result.Account.Balance - sinceTo
+
+		entries, err := q.ListEntriesInPeriod(ctx, ListEntriesInPeriodParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		var transferIDs []int64
+		for _, entry := range entries {
+			if entry.TransferID.Valid {
+				transferIDs = append(transferIDs, entry.TransferID.Int64)
+			}
+		}
+
+		transfers, err := q.ListTransfersByIDs(ctx, transferIDs)
+		if err != nil {
+			return err
+		}
+
+		transfersByID := make(map[int64]*ListTransfersByIDsRow, len(transfers))
+		for i := range transfers {
+			transfersByID[transfers[i].Transfer.ID] = &transfers[i]
+		}
+
+		balance := result.OpeningBalance
+		result.Lines = make([]StatementLine, len(entries))
+		for i, entry := range entries {
+			balance += entry.Amount
+			line := StatementLine{
+				Entry:   entry,
+				Balance: balance,
+			}
+
+			if transfer, ok := transfersByID[entry.TransferID.Int64]; ok && entry.TransferID.Valid {
+				line.Transfer = transfer
+				line.CounterpartyAccountID = transfer.Transfer.FromAccountID
+				if transfer.Transfer.FromAccountID == arg.AccountID {
+					line.CounterpartyAccountID = transfer.Transfer.ToAccountID
+				}
+			}
+
+			result.Lines[i] = line
+		}
+
+		return nil
+	})
+
+	return result, err
+}
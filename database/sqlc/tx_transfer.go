@@ -1,12 +1,42 @@
 package db
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Different types of error returned by the TransferTx function
+var (
+	// ErrInsufficientFunds is returned when the debit would take the source account below its overdraft limit
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrIdempotencyKeyExists is returned when another transfer already recorded the same idempotency key
+	ErrIdempotencyKeyExists = errors.New("idempotency key already used")
+	// ErrInvalidTransferAmount is returned when the amount is not positive or above MaxTransferAmount
+	ErrInvalidTransferAmount = errors.New("transfer amount out of range")
+)
+
+// MaxTransferAmount bounds the amount of a single transfer, and the overdraft limit of an account,
+// in minor units. It keeps the balance arithmetic of the funds check far from overflowing int64.
+const MaxTransferAmount = int64(1_000_000_000_000_000)
 
 // TransferTxParams contains the input parameters of the transfer transaction
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// Idempotency is optional; when set, the result is recorded under the key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// IdempotencyParams identifies the client request a transfer was made for
+type IdempotencyParams struct {
+	Username    string
+	Key         string
+	RequestHash string
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -18,51 +48,152 @@ type TransferTxResult struct {
 	ToEntry     Entry    `json:"to_entry"`
 }
 
-// TransferTx performs a money transfer from one account to the other.
-// It creates the transfer, add account entries, and update accounts' balance within a database transaction
+// FxTransferTxParams contains the input parameters of the cross-currency transfer transaction
+type FxTransferTxParams struct {
+	TransferTxParams
+	// ToAmount is credited to the destination account, in its own currency
+	ToAmount int64 `json:"to_amount"`
+	// FxRate is the rate Amount was converted into ToAmount at
+	FxRate string `json:"fx_rate"`
+}
+
+// TransferTx performs a money transfer between two accounts of the same currency.
+// It creates the transfer, add account entries, and update accounts' balance within a database transaction.
+// The transfer is rejected with ErrInsufficientFunds if the source account cannot cover the amount.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return store.FxTransferTx(ctx, FxTransferTxParams{
+		TransferTxParams: arg,
+		ToAmount:         arg.Amount,
+		FxRate:           "1",
+	})
+}
+
+// FxTransferTx performs a money transfer between accounts of different currencies.
+// The source account is debited Amount in its currency and the destination account
+// is credited ToAmount in its own, and the applied rate is recorded on the transfer.
+// Transfers from a frozen or closed account, or to a closed one, are rejected with ErrAccountFrozen
+// or ErrAccountClosed. A frozen account only refuses incoming money when its freeze says so.
+func (store *SQLStore) FxTransferTx(ctx context.Context, arg FxTransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
+	if arg.Amount <= 0 || arg.Amount > MaxTransferAmount || arg.ToAmount <= 0 || arg.ToAmount > MaxTransferAmount {
+		return result, ErrInvalidTransferAmount
+	}
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+	err := store.execTx(ctx, func(q *Queries) error {
+		fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
+		if err := checkActive(fromAccount); err != nil {
 			return err
 		}
+		if err := checkCanReceive(toAccount); err != nil {
+			return err
+		}
+
+		if arg.Amount > fromAccount.Balance+fromAccount.OverdraftLimit {
+			return ErrInsufficientFunds
+		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+		result, err = moveMoney(ctx, q, arg)
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+		if arg.Idempotency != nil {
+			return saveIdempotencyKey(ctx, q, *arg.Idempotency, result)
 		}
 
-		return err
+		return nil
 	})
 
 	return result, err
 }
 
+// moveMoney records a transfer and its entries, and updates the balances of both accounts.
+// The accounts must already be locked and checked by the caller's transaction.
+func moveMoney(ctx context.Context, q *Queries, arg FxTransferTxParams) (result TransferTxResult, err error) {
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToAmount:      arg.ToAmount,
+		FxRate:        arg.FxRate,
+	})
+	if err != nil {
+		return
+	}
+
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.ToAmount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return
+	}
+
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -arg.Amount)
+	}
+	return
+}
+
+// lockAccounts locks both accounts of a transfer in ID order, so that concurrent
+// transfers in opposite directions cannot deadlock
+func lockAccounts(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64) (fromAccount Account, toAccount Account, err error) {
+	if fromAccountID < toAccountID {
+		fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
+		if err != nil {
+			return
+		}
+		toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+		return
+	}
+
+	toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+	if err != nil {
+		return
+	}
+	fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
+	return
+}
+
+// saveIdempotencyKey records the transfer result so that a retried request can be replayed.
+// A concurrent request with the same key blocks on the primary key until this transaction ends.
+func saveIdempotencyKey(ctx context.Context, q *Queries, arg IdempotencyParams, result TransferTxResult) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.Key,
+		RequestHash:    arg.RequestHash,
+		Response:       response,
+	})
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return ErrIdempotencyKeyExists
+	}
+	return err
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+)
+
+// UnlockAccountTxParams contains the input parameters of the unlock account transaction
+type UnlockAccountTxParams struct {
+	UnlockId   int64
+	SecretCode string
+}
+
+// UnlockAccountTxResult is the result of the unlock account transaction
+type UnlockAccountTxResult struct {
+	UnlockAccount UnlockAccount
+}
+
+// UnlockAccountTx marks an unlock record as used and forgets the failed logins of its user within a database transaction.
+// It returns sql.ErrNoRows if the secret code is wrong, already used or expired.
+func (store *SQLStore) UnlockAccountTx(ctx context.Context, arg UnlockAccountTxParams) (UnlockAccountTxResult, error) {
+	var result UnlockAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.UnlockAccount, err = q.UpdateUnlockAccount(ctx, UpdateUnlockAccountParams{
+			ID:         arg.UnlockId,
+			SecretCode: arg.SecretCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteLoginFailures(ctx, result.UnlockAccount.Username)
+	})
+
+	return result, err
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateUserTxParams contains the input parameters of the update user transaction
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	// KeepSessionID is the session left alive when the password changes, usually the caller's own
+	KeepSessionID uuid.NullUUID
+}
+
+// UpdateUserTxResult is the result of the update user transaction
+type UpdateUserTxResult struct {
+	User            User
+	BlockedSessions int64
+}
+
+// UpdateUserTx updates a user within a database transaction.
+// When the password changes, it stamps password_changed_at so older access tokens are rejected,
+// and blocks every session of the user except KeepSessionID.
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		updateArg := arg.UpdateUserParams
+		if updateArg.HashedPassword.Valid {
+			updateArg.PasswordChangedAt = sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			}
+		}
+
+		result.User, err = q.UpdateUser(ctx, updateArg)
+		if err != nil {
+			return err
+		}
+
+		if !updateArg.HashedPassword.Valid {
+			return nil
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessionsExcept(ctx, BlockUserSessionsExceptParams{
+			Username:      result.User.Username,
+			KeepSessionID: arg.KeepSessionID,
+		})
+		return err
+	})
+
+	return result, err
+}
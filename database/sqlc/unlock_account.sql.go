@@ -0,0 +1,69 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: unlock_account.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createUnlockAccount = `-- name: CreateUnlockAccount :one
+INSERT INTO unlock_accounts (
+    username,
+    secret_code
+) VALUES (
+    $1, $2
+) RETURNING id, username, secret_code, is_used, created_at, expired_at
+`
+
+type CreateUnlockAccountParams struct {
+	Username   string `json:"username"`
+	SecretCode string `json:"secret_code"`
+}
+
+func (q *Queries) CreateUnlockAccount(ctx context.Context, arg CreateUnlockAccountParams) (UnlockAccount, error) {
+	row := q.db.QueryRowContext(ctx, createUnlockAccount, arg.Username, arg.SecretCode)
+	var i UnlockAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.SecretCode,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiredAt,
+	)
+	return i, err
+}
+
+const updateUnlockAccount = `-- name: UpdateUnlockAccount :one
+UPDATE unlock_accounts
+SET
+    is_used = TRUE
+WHERE
+    id = $1
+    AND secret_code = $2
+    AND is_used = FALSE
+    AND expired_at > now()
+RETURNING id, username, secret_code, is_used, created_at, expired_at
+`
+
+type UpdateUnlockAccountParams struct {
+	ID         int64  `json:"id"`
+	SecretCode string `json:"secret_code"`
+}
+
+func (q *Queries) UpdateUnlockAccount(ctx context.Context, arg UpdateUnlockAccountParams) (UnlockAccount, error) {
+	row := q.db.QueryRowContext(ctx, updateUnlockAccount, arg.ID, arg.SecretCode)
+	var i UnlockAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.SecretCode,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiredAt,
+	)
+	return i, err
+}
@@ -8,6 +8,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -18,7 +19,7 @@ INSERT INTO users (
   email
 ) VALUES (
   $1, $2, $3, $4
-) RETURNING id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified
+) RETURNING id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step
 `
 
 type CreateUserParams struct {
@@ -45,12 +46,94 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const enableUserMfa = `-- name: EnableUserMfa :one
+UPDATE users
+SET
+  is_mfa_enabled = true,
+  totp_last_used_step = $1
+WHERE
+  username = $2 AND
+  totp_secret IS NOT NULL
+RETURNING id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step
+`
+
+type EnableUserMfaParams struct {
+	TotpLastUsedStep int64  `json:"totp_last_used_step"`
+	Username         string `json:"username"`
+}
+
+func (q *Queries) EnableUserMfa(ctx context.Context, arg EnableUserMfaParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableUserMfa, arg.TotpLastUsedStep, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const getUserAuthorization = `-- name: GetUserAuthorization :one
+SELECT password_changed_at, role FROM users
+WHERE username = $1 LIMIT 1
+`
+
+type GetUserAuthorizationRow struct {
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	Role              string    `json:"role"`
+}
+
+func (q *Queries) GetUserAuthorization(ctx context.Context, username string) (GetUserAuthorizationRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserAuthorization, username)
+	var i GetUserAuthorizationRow
+	err := row.Scan(&i.PasswordChangedAt, &i.Role)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified FROM users
+SELECT id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -66,12 +149,16 @@ func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified FROM users
+SELECT id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -87,6 +174,44 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const setUserTotpSecret = `-- name: SetUserTotpSecret :one
+UPDATE users
+SET totp_secret = $1
+WHERE
+  username = $2 AND
+  is_mfa_enabled = false
+RETURNING id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step
+`
+
+type SetUserTotpSecretParams struct {
+	TotpSecret sql.NullString `json:"totp_secret"`
+	Username   string         `json:"username"`
+}
+
+func (q *Queries) SetUserTotpSecret(ctx context.Context, arg SetUserTotpSecretParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserTotpSecret, arg.TotpSecret, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
 	)
 	return i, err
 }
@@ -101,7 +226,7 @@ SET
   is_email_verified = COALESCE($5, is_email_verified)
 WHERE
   id = $6
-RETURNING id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified
+RETURNING id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step
 `
 
 type UpdateUserParams struct {
@@ -132,6 +257,63 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1
+WHERE id = $2
+RETURNING id, username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, totp_secret, is_mfa_enabled, totp_last_used_step
+`
+
+type UpdateUserRoleParams struct {
+	Role string `json:"role"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Role, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.TotpSecret,
+		&i.IsMfaEnabled,
+		&i.TotpLastUsedStep,
 	)
 	return i, err
 }
+
+const updateUserTotpStep = `-- name: UpdateUserTotpStep :execrows
+UPDATE users
+SET totp_last_used_step = $1
+WHERE
+  username = $2 AND
+  totp_last_used_step < $1
+`
+
+type UpdateUserTotpStepParams struct {
+	TotpLastUsedStep int64  `json:"totp_last_used_step"`
+	Username         string `json:"username"`
+}
+
+func (q *Queries) UpdateUserTotpStep(ctx context.Context, arg UpdateUserTotpStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserTotpStep, arg.TotpLastUsedStep, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
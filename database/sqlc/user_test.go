@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/forabbie/vank-app/util"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,6 +28,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, util.DepositorRole, user.Role)
 
 	require.NotZero(t, user.PasswordChangedAt)
 	require.NotZero(t, user.CreatedAt)
@@ -145,3 +147,99 @@ func TestUpdateUserAllFields(t *testing.T) {
 	require.NotEqual(t, oldUser.FullName, updatedUser.FullName)
 	require.Equal(t, newFullName, updatedUser.FullName)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	oldUser := createRandomUser(t)
+
+	newUser, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		ID:   oldUser.ID,
+		Role: util.BankerRole,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.BankerRole, newUser.Role)
+	require.Equal(t, oldUser.Username, newUser.Username)
+
+	// the role_supported constraint rejects unknown roles
+	_, err = testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		ID:   oldUser.ID,
+		Role: "superuser",
+	})
+	require.Error(t, err)
+}
+
+func TestUpdateUserTxPasswordChange(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	currentSession := createRandomSession(t, user.Username)
+	otherSession := createRandomSession(t, user.Username)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	result, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			ID:             user.ID,
+			HashedPassword: sql.NullString{String: hashedPassword, Valid: true},
+		},
+		KeepSessionID: uuid.NullUUID{UUID: currentSession.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, result.User.HashedPassword)
+	require.True(t, result.User.PasswordChangedAt.After(user.PasswordChangedAt))
+	require.Equal(t, int64(1), result.BlockedSessions)
+
+	session, err := testQueries.GetSession(context.Background(), currentSession.ID)
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+
+	session, err = testQueries.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+}
+
+func TestUpdateUserTxWithoutPasswordChange(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+
+	result, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			ID:       user.ID,
+			FullName: sql.NullString{String: util.RandomOwner(), Valid: true},
+		},
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, user.PasswordChangedAt, result.User.PasswordChangedAt, time.Second)
+	require.Zero(t, result.BlockedSessions)
+
+	session, err = testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	verifyEmail, err := store.CreateVerifyEmail(context.Background(), CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	arg := VerifyEmailTxParams{
+		EmailId:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	}
+	result, err := store.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.VerifyEmail.IsUsed)
+	require.True(t, result.User.IsEmailVerified)
+	require.Equal(t, user.Username, result.User.Username)
+
+	// the link can only be used once
+	_, err = store.VerifyEmailTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
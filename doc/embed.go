@@ -0,0 +1,8 @@
+package doc
+
+import "embed"
+
+// Swagger holds the generated OpenAPI document and the Swagger UI page that renders it
+//
+//go:embed swagger
+var Swagger embed.FS
@@ -0,0 +1,133 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/forabbie/vank-app/currency"
+	db "github.com/forabbie/vank-app/database/sqlc"
+)
+
+// Different types of error returned when converting an amount
+var (
+	// ErrRateNotFound is returned when no rate between the two currencies is valid yet
+	ErrRateNotFound = errors.New("no exchange rate available")
+	// ErrAmountTooSmall is returned when the converted amount rounds down to nothing
+	ErrAmountTooSmall = errors.New("amount too small to convert")
+	// ErrAmountTooLarge is returned when the converted amount does not fit in an int64
+	ErrAmountTooLarge = errors.New("converted amount too large")
+)
+
+// Conversion is an amount converted from one currency into another
+type Conversion struct {
+	Amount   int64
+	ToAmount int64
+	Rate     string
+}
+
+// Quote converts an amount of the from currency into the to currency at the latest valid rate
+func Quote(ctx context.Context, store db.Querier, currencies *currency.Registry, amount int64, from string, to string) (Conversion, error) {
+	rate, err := store.GetLatestFxRate(ctx, db.GetLatestFxRateParams{
+		Base:  from,
+		Quote: to,
+		At:    time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Conversion{}, fmt.Errorf("%w from %s to %s", ErrRateNotFound, from, to)
+		}
+		return Conversion{}, err
+	}
+
+	fromCurrency, ok := currencies.Get(ctx, from)
+	if !ok {
+		return Conversion{}, fmt.Errorf("unknown currency %s", from)
+	}
+	toCurrency, ok := currencies.Get(ctx, to)
+	if !ok {
+		return Conversion{}, fmt.Errorf("unknown currency %s", to)
+	}
+
+	toAmount, err := Convert(amount, rate.Rate, fromCurrency.Exponent, toCurrency.Exponent)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	return Conversion{
+		Amount:   amount,
+		ToAmount: toAmount,
+		Rate:     rate.Rate,
+	}, nil
+}
+
+// AtLeast reports whether an amount of the from currency is worth at least threshold minor units
+// of the reference currency at the latest valid rate. Without a rate to compare at, it errs on
+// the side of caution and reports true.
+func AtLeast(ctx context.Context, store db.Querier, currencies *currency.Registry, amount int64, from string, threshold int64, reference string) (bool, error) {
+	if from == reference {
+		return amount >= threshold, nil
+	}
+
+	conversion, err := Quote(ctx, store, currencies, amount, from, reference)
+	switch {
+	case err == nil:
+		return conversion.ToAmount >= threshold, nil
+	case errors.Is(err, ErrAmountTooSmall):
+		return threshold <= 0, nil
+	case errors.Is(err, ErrAmountTooLarge), errors.Is(err, ErrRateNotFound):
+		return true, nil
+	}
+	return false, err
+}
+
+// Convert converts an amount in minor units of one currency into minor units of another.
+// The result is rounded down, so the bank never credits more than the rate gives.
+func Convert(amount int64, rate string, fromExponent int32, toExponent int32) (int64, error) {
+	r, err := parseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), r)
+
+	// Move from the minor units of one currency to the minor units of the other
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExponent-fromExponent))), nil)
+	if toExponent > fromExponent {
+		value.Mul(value, new(big.Rat).SetInt(scale))
+	} else {
+		value.Quo(value, new(big.Rat).SetInt(scale))
+	}
+
+	// Quo truncates towards zero, which rounds down positive amounts
+	converted := new(big.Int).Quo(value.Num(), value.Denom())
+	if !converted.IsInt64() {
+		return 0, ErrAmountTooLarge
+	}
+	if converted.Sign() <= 0 {
+		return 0, ErrAmountTooSmall
+	}
+
+	return converted.Int64(), nil
+}
+
+func parseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok {
+		return nil, fmt.Errorf("invalid rate %q", rate)
+	}
+	if r.Sign() <= 0 {
+		return nil, fmt.Errorf("rate %q must be positive", rate)
+	}
+	return r, nil
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,178 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/currency"
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name         string
+		amount       int64
+		rate         string
+		fromExponent int32
+		toExponent   int32
+		expected     int64
+		err          error
+	}{
+		{
+			name:         "SameExponent",
+			amount:       10000,
+			rate:         "0.92",
+			fromExponent: 2,
+			toExponent:   2,
+			expected:     9200,
+		},
+		{
+			name:         "RoundsDown",
+			amount:       1,
+			rate:         "1.999",
+			fromExponent: 2,
+			toExponent:   2,
+			expected:     1,
+		},
+		{
+			name:         "ToFewerDigits",
+			amount:       100, // 1.00 USD
+			rate:         "151.23",
+			fromExponent: 2,
+			toExponent:   0,
+			expected:     151, // 151 JPY
+		},
+		{
+			name:         "ToMoreDigits",
+			amount:       1000, // 1000 JPY
+			rate:         "0.0066",
+			fromExponent: 0,
+			toExponent:   3,
+			expected:     6600, // 6.600 in a currency with 3 digits
+		},
+		{
+			name:         "TooSmall",
+			amount:       1,
+			rate:         "0.5",
+			fromExponent: 2,
+			toExponent:   2,
+			err:          ErrAmountTooSmall,
+		},
+		{
+			name:         "TooLarge",
+			amount:       math.MaxInt64,
+			rate:         "2",
+			fromExponent: 2,
+			toExponent:   2,
+			err:          ErrAmountTooLarge,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := Convert(tc.amount, tc.rate, tc.fromExponent, tc.toExponent)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
+
+func TestConvertInvalidRate(t *testing.T) {
+	for _, rate := range []string{"", "abc", "0", "-1.2"} {
+		_, err := Convert(100, rate, 2, 2)
+		require.Error(t, err, rate)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return([]db.Currency{
+			{Code: "USD", Exponent: 2, IsEnabled: true},
+			{Code: "JPY", Exponent: 0, IsEnabled: true},
+		}, nil)
+	currencies := currency.NewRegistry(store, time.Minute)
+
+	store.EXPECT().
+		GetLatestFxRate(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.GetLatestFxRateParams) (db.FxRate, error) {
+			require.Equal(t, "USD", arg.Base)
+			require.Equal(t, "JPY", arg.Quote)
+			require.WithinDuration(t, time.Now(), arg.At, time.Second)
+			return db.FxRate{Base: "USD", Quote: "JPY", Rate: "151.23"}, nil
+		})
+
+	conversion, err := Quote(context.Background(), store, currencies, 1050, "USD", "JPY")
+	require.NoError(t, err)
+	require.Equal(t, Conversion{Amount: 1050, ToAmount: 1587, Rate: "151.23"}, conversion)
+
+	store.EXPECT().
+		GetLatestFxRate(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.FxRate{}, sql.ErrNoRows)
+
+	_, err = Quote(context.Background(), store, currencies, 1050, "JPY", "USD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestAtLeast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return([]db.Currency{
+			{Code: "USD", Exponent: 2, IsEnabled: true},
+			{Code: "JPY", Exponent: 0, IsEnabled: true},
+		}, nil)
+	currencies := currency.NewRegistry(store, time.Minute)
+
+	// the reference currency is compared as is
+	reached, err := AtLeast(context.Background(), store, currencies, 100000, "USD", 100000, "USD")
+	require.NoError(t, err)
+	require.True(t, reached)
+
+	// 100000 JPY are not 1000.00 USD, even though the minor units match
+	store.EXPECT().
+		GetLatestFxRate(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(db.FxRate{Base: "JPY", Quote: "USD", Rate: "0.0066"}, nil)
+
+	reached, err = AtLeast(context.Background(), store, currencies, 100000, "JPY", 100000, "USD")
+	require.NoError(t, err)
+	require.False(t, reached)
+
+	reached, err = AtLeast(context.Background(), store, currencies, 200000, "JPY", 100000, "USD")
+	require.NoError(t, err)
+	require.True(t, reached)
+
+	// without a rate the amount is treated as reaching the threshold
+	store.EXPECT().
+		GetLatestFxRate(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.FxRate{}, sql.ErrNoRows)
+
+	reached, err = AtLeast(context.Background(), store, currencies, 1, "JPY", 100000, "USD")
+	require.NoError(t, err)
+	require.True(t, reached)
+}
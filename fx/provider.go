@@ -0,0 +1,65 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Rate is the price of one unit of the base currency in the quote currency
+type Rate struct {
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+	// Rate is a decimal string, so that it is never rounded through a float
+	Rate string `json:"rate"`
+	// ValidFrom is when the rate starts being applied to transfers
+	ValidFrom time.Time `json:"valid_from"`
+}
+
+// RateProvider is a source of exchange rates, such as a market data feed
+type RateProvider interface {
+	// Rates returns the rates currently published by the provider
+	Rates(ctx context.Context) ([]Rate, error)
+}
+
+// StaticFileProvider serves rates from a JSON file holding a list of rates, for local use.
+// The file is read again on every call, so editing it publishes new rates.
+type StaticFileProvider struct {
+	path string
+}
+
+// NewStaticFileProvider creates a provider reading the rates from the given file
+func NewStaticFileProvider(path string) *StaticFileProvider {
+	return &StaticFileProvider{
+		path: path,
+	}
+}
+
+// Rates reads the rates from the file.
+// A rate without valid_from is valid from the last time the file was modified.
+func (provider *StaticFileProvider) Rates(ctx context.Context) ([]Rate, error) {
+	info, err := os.Stat(provider.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+
+	data, err := os.ReadFile(provider.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+
+	var rates []Rate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %w", err)
+	}
+
+	for i := range rates {
+		if rates[i].ValidFrom.IsZero() {
+			rates[i].ValidFrom = info.ModTime().UTC().Truncate(time.Second)
+		}
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,72 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	data := `[
+		{"base": "USD", "quote": "EUR", "rate": "0.92", "valid_from": "2026-01-01T00:00:00Z"},
+		{"base": "EUR", "quote": "USD", "rate": "1.087"}
+	]`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	rates, err := NewStaticFileProvider(path).Rates(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Rate{
+		{Base: "USD", Quote: "EUR", Rate: "0.92", ValidFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Base: "EUR", Quote: "USD", Rate: "1.087", ValidFrom: info.ModTime().UTC().Truncate(time.Second)},
+	}, rates)
+
+	_, err = NewStaticFileProvider(filepath.Join(t.TempDir(), "missing.json")).Rates(context.Background())
+	require.Error(t, err)
+}
+
+type fakeProvider []Rate
+
+func (provider fakeProvider) Rates(ctx context.Context) ([]Rate, error) {
+	return provider, nil
+}
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validFrom := time.Now().UTC().Truncate(time.Second)
+	provider := fakeProvider{
+		{Base: "USD", Quote: "EUR", Rate: "0.92", ValidFrom: validFrom},
+		{Base: "EUR", Quote: "USD", Rate: "1.087", ValidFrom: validFrom},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	for _, rate := range provider {
+		store.EXPECT().
+			CreateFxRate(gomock.Any(), gomock.Eq(db.CreateFxRateParams{
+				Base:      rate.Base,
+				Quote:     rate.Quote,
+				Rate:      rate.Rate,
+				ValidFrom: rate.ValidFrom,
+			})).
+			Times(1)
+	}
+
+	require.NoError(t, Refresh(context.Background(), store, provider))
+
+	// a bad rate is rejected before anything is saved
+	store.EXPECT().CreateFxRate(gomock.Any(), gomock.Any()).Times(0)
+	err := Refresh(context.Background(), store, fakeProvider{provider[0], {Base: "USD", Quote: "EUR", Rate: "-1"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+)
+
+// Refresh saves the rates published by the provider into the rates table.
+// A rate already stored for the same pair and valid_from is kept as it is, even if the
+// provider now publishes a different one, so it is safe to call repeatedly and never
+// changes the rate past transfers were converted at.
+func Refresh(ctx context.Context, store db.Querier, provider RateProvider) error {
+	rates, err := provider.Rates(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Reject a bad feed as a whole rather than storing part of it
+	for _, rate := range rates {
+		if _, err := parseRate(rate.Rate); err != nil {
+			return fmt.Errorf("invalid %s/%s rate: %w", rate.Base, rate.Quote, err)
+		}
+	}
+
+	for _, rate := range rates {
+		err := store.CreateFxRate(ctx, db.CreateFxRateParams{
+			Base:      rate.Base,
+			Quote:     rate.Quote,
+			Rate:      rate.Rate,
+			ValidFrom: rate.ValidFrom,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot save %s/%s rate: %w", rate.Base, rate.Quote, err)
+		}
+	}
+
+	return nil
+}
+
+// RunRefresher refreshes the rates from the provider every interval until the context is done
+func RunRefresher(ctx context.Context, store db.Querier, provider RateProvider, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := Refresh(ctx, store, provider); err != nil {
+			// Transfers keep using the last stored rates until the provider recovers
+			log.Printf("cannot refresh fx rates: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/forabbie/vank-app/token"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+)
+
+// authorizeUser verifies the bearer access token sent in the request metadata.
+// Tokens issued before the user last changed their password are rejected,
+// and the returned payload carries the current role of the user.
+func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing authorization header")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != authorizationBearer {
+		return nil, fmt.Errorf("unsupported authorization type: %s", authType)
+	}
+
+	accessToken := fields[1]
+	payload, err := server.tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %s", err)
+	}
+
+	user, err := server.store.GetUserAuthorization(ctx, payload.Username)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find user: %s", err)
+	}
+
+	if payload.IssuedAt.Before(user.PasswordChangedAt) {
+		return nil, fmt.Errorf("access token was issued before the password was changed")
+	}
+
+	// The role may have changed since the token was issued
+	current := *payload
+	current.Role = user.Role
+	return &current, nil
+}
@@ -0,0 +1,109 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		IsMfaEnabled:      user.IsMfaEnabled,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+// convertAccount formats the balance and overdraft limit with the exponent of the currency
+func (server *Server) convertAccount(ctx context.Context, account db.Account) (*pb.Account, error) {
+	exponent, err := server.currencies.Exponent(ctx, account.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &pb.Account{
+		Id:             account.ID,
+		Owner:          account.Owner,
+		Balance:        money.New(account.Balance, account.Currency, exponent).String(),
+		Currency:       account.Currency,
+		OverdraftLimit: money.New(account.OverdraftLimit, account.Currency, exponent).String(),
+		CreatedAt:      timestamppb.New(account.CreatedAt),
+		Status:         account.Status,
+	}
+	if account.ClosedAt.Valid {
+		rsp.ClosedAt = timestamppb.New(account.ClosedAt.Time)
+	}
+	return rsp, nil
+}
+
+// convertAccountDetails also tells why the account is frozen, which
+// must not leak to other users, e.g. in the response of a transfer
+func (server *Server) convertAccountDetails(ctx context.Context, account db.Account) (*pb.Account, error) {
+	rsp, err := server.convertAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Status == db.AccountStatusFrozen {
+		rsp.FrozenReason = account.FrozenReason.String
+		rsp.FreezeBlocksIncoming = account.FreezeBlocksIncoming
+	}
+	return rsp, nil
+}
+
+func (server *Server) convertAccounts(ctx context.Context, accounts []db.Account) ([]*pb.Account, error) {
+	rsp := make([]*pb.Account, len(accounts))
+	for i, account := range accounts {
+		var err error
+		rsp[i], err = server.convertAccount(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rsp, nil
+}
+
+// convertTransfer formats the amount in the currency of the source account,
+// and the converted amount in the one of the destination account
+func (server *Server) convertTransfer(ctx context.Context, transfer db.Transfer, fromCurrency string, toCurrency string) (*pb.Transfer, error) {
+	amount, err := server.currencies.Amount(ctx, transfer.Amount, fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	toAmount, err := server.currencies.Amount(ctx, transfer.ToAmount, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        amount.String(),
+		ToAmount:      toAmount.String(),
+		FxRate:        transfer.FxRate,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}, nil
+}
+
+func (server *Server) convertEntry(ctx context.Context, entry db.Entry, currency string) (*pb.Entry, error) {
+	amount, err := server.currencies.Amount(ctx, entry.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    amount.String(),
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}, nil
+}
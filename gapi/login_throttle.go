@@ -0,0 +1,32 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/forabbie/vank-app/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// allowLoginAttempt returns a ResourceExhausted error while the username or client IP is throttled
+func (server *Server) allowLoginAttempt(ctx context.Context, username string, clientIP string) error {
+	wait, locked, err := server.loginThrottle.RetryAfter(ctx, username, clientIP)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check login attempts")
+	}
+	if wait > 0 {
+		return status.Errorf(codes.ResourceExhausted, "%s (retry after %s)", auth.LoginError(locked), wait.Round(time.Second))
+	}
+	return nil
+}
+
+// peerIP returns the client IP of the request without the port
+func peerIP(mtdt *Metadata) string {
+	// Direct gRPC peers come with a port, which changes on every connection
+	if host, _, err := net.SplitHostPort(mtdt.ClientIP); err == nil {
+		return host
+	}
+	return mtdt.ClientIP
+}
@@ -0,0 +1,110 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/token"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
+	config := util.Config{
+		TokenSymmetricKey:     util.RandomString(32),
+		CursorSigningKey:      util.RandomString(32),
+		AccessTokenDuration:   time.Minute,
+		CurrencyCacheDuration: time.Minute,
+	}
+
+	var server *Server
+
+	// Unless a test stubs it first, users have never changed their password
+	// and still have the role their token was issued with
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			GetUserAuthorization(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			DoAndReturn(func(ctx context.Context, username string) (db.GetUserAuthorizationRow, error) {
+				return db.GetUserAuthorizationRow{Role: tokenRole(t, server.tokenMaker, ctx)}, nil
+			})
+
+		// and only the currencies seeded by the migrations exist
+		mockStore.EXPECT().
+			ListCurrencies(gomock.Any()).
+			AnyTimes().
+			Return(seededCurrencies(), nil)
+	}
+
+	server, err := NewServer(config, store, taskDistributor)
+	require.NoError(t, err)
+
+	return server
+}
+
+// tokenRole returns the role in the access token of the request being served
+func tokenRole(t *testing.T, tokenMaker token.Maker, ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	require.True(t, ok)
+
+	fields := strings.Fields(md.Get(authorizationHeader)[0])
+	require.Len(t, fields, 2)
+
+	payload, err := tokenMaker.VerifyToken(fields[1])
+	require.NoError(t, err)
+	return payload.Role
+}
+
+func seededCurrencies() []db.Currency {
+	return []db.Currency{
+		{Code: util.CAD, Exponent: 2, IsEnabled: true},
+		{Code: util.EUR, Exponent: 2, IsEnabled: true},
+		{Code: util.USD, Exponent: 2, IsEnabled: true},
+	}
+}
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, role string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, role, duration)
+	require.NoError(t, err)
+
+	bearerToken := fmt.Sprintf("%s %s", authorizationBearer, accessToken)
+	md := metadata.MD{
+		authorizationHeader: []string{
+			bearerToken,
+		},
+	}
+
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		ID:             util.RandomInt(1, 1000),
+		Username:       util.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	}
+	return
+}
+
+func randomAccount(owner string, currency string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomMoney(),
+		Currency: currency,
+	}
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/forabbie/vank-app/auth"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/money"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// createMfaChallenge answers a correct password of a user with two-factor authentication
+// with a short-lived token that is exchanged for the session with VerifyLoginMfa
+func (server *Server) createMfaChallenge(ctx context.Context, user db.User) (*pb.LoginUserResponse, error) {
+	challenge, err := server.store.CreateMfaChallenge(ctx, db.CreateMfaChallengeParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(server.config.MfaChallengeDuration),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create mfa challenge")
+	}
+
+	rsp := &pb.LoginUserResponse{
+		MfaRequired:       true,
+		MfaToken:          challenge.ID.String(),
+		MfaTokenExpiresAt: timestamppb.New(challenge.ExpiresAt),
+	}
+	return rsp, nil
+}
+
+// validTransferMfa requires a fresh TOTP code for transfers worth at least the configured amount
+// of the reference currency from users with two-factor authentication
+func (server *Server) validTransferMfa(ctx context.Context, username string, amount money.Amount, code string) error {
+	user, required, err := auth.TransferNeedsMfa(ctx, server.store, server.currencies, server.config, username, amount)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check two-factor requirement: %s", err)
+	}
+	if !required {
+		return nil
+	}
+
+	if code == "" {
+		return status.Errorf(codes.PermissionDenied, "%s", auth.ErrMfaRequired)
+	}
+
+	clientIP := peerIP(server.extractMetadata(ctx))
+	if err := server.allowLoginAttempt(ctx, username, clientIP); err != nil {
+		return err
+	}
+
+	valid, err := auth.CheckMfaCode(ctx, server.store, server.config.MfaEncryptionKey, user, code, false)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check two-factor code")
+	}
+	if !valid {
+		if err := server.loginThrottle.RecordFailure(ctx, username, clientIP, true); err != nil {
+			return status.Errorf(codes.Internal, "failed to record login attempt")
+		}
+		return status.Errorf(codes.PermissionDenied, "%s", auth.ErrInvalidMfaCode)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/forabbie/vank-app/currency"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateAccountRequest(ctx, server.currencies, req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    authPayload.Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	}
+
+	account, err := server.store.CreateAccount(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				return nil, status.Errorf(codes.AlreadyExists, "account with this currency already exists")
+			case "foreign_key_violation":
+				return nil, status.Errorf(codes.NotFound, "owner not found")
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	rspAccount, err := server.convertAccount(ctx, account)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to format account: %s", err)
+	}
+
+	rsp := &pb.CreateAccountResponse{
+		Account: rspAccount,
+	}
+	return rsp, nil
+}
+
+func validateCreateAccountRequest(ctx context.Context, currencies *currency.Registry, req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validator.ValidateCurrency(ctx, currencies, req.GetCurrency()); err != nil {
+		violations = append(violations, util.CreateFieldViolation("currency", err))
+	}
+	return violations
+}
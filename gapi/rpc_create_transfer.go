@@ -0,0 +1,227 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/forabbie/vank-app/currency"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/fx"
+	"github.com/forabbie/vank-app/idempotency"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateTransferRequest(ctx, server.currencies, req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	amount, err := server.currencies.Parse(ctx, req.GetAmount(), req.GetCurrency())
+	if err == nil && (amount.Sign() <= 0 || amount.Value > db.MaxTransferAmount) {
+		err = db.ErrInvalidTransferAmount
+	}
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			util.CreateFieldViolation("amount", err),
+		})
+	}
+
+	fingerprint := idempotency.Transfer{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        amount.String(),
+		Currency:      req.GetCurrency(),
+	}
+	idempotencyArg, err := idempotency.Params(server.extractMetadata(ctx).IdempotencyKey, authPayload.Username, fingerprint)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			util.CreateFieldViolation(idempotencyKeyHeader, err),
+		})
+	}
+
+	// A retried request gets the response of the original one instead of moving money again
+	if idempotencyArg != nil {
+		if rsp, err := server.replayTransfer(ctx, *idempotencyArg); rsp != nil || err != nil {
+			return rsp, err
+		}
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	// The destination account may hold another currency, the amount is then converted
+	toAccount, err := server.existingAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := server.validTransferMfa(ctx, authPayload.Username, amount, req.GetTotpCode()); err != nil {
+		return nil, err
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        amount.Value,
+		Idempotency:   idempotencyArg,
+	}
+
+	var result db.TransferTxResult
+	if toAccount.Currency == fromAccount.Currency {
+		result, err = server.store.TransferTx(ctx, arg)
+	} else {
+		var conversion fx.Conversion
+		conversion, err = fx.Quote(ctx, server.store, server.currencies, amount.Value, fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			if errors.Is(err, fx.ErrRateNotFound) || errors.Is(err, fx.ErrAmountTooSmall) || errors.Is(err, fx.ErrAmountTooLarge) {
+				return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+			}
+			return nil, status.Errorf(codes.Internal, "failed to convert amount: %s", err)
+		}
+
+		result, err = server.store.FxTransferTx(ctx, db.FxTransferTxParams{
+			TransferTxParams: arg,
+			ToAmount:         conversion.ToAmount,
+			FxRate:           conversion.Rate,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrInvalidTransferAmount) || errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		// A concurrent request with the same key committed first
+		if errors.Is(err, db.ErrIdempotencyKeyExists) {
+			if rsp, err := server.replayTransfer(ctx, *idempotencyArg); rsp != nil || err != nil {
+				return rsp, err
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer: %s", err)
+	}
+
+	rsp, err := server.newCreateTransferResponse(ctx, result)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to format transfer: %s", err)
+	}
+	return rsp, nil
+}
+
+// replayTransfer returns the response recorded for an idempotency key,
+// and nil without error if the key has not been used yet
+func (server *Server) replayTransfer(ctx context.Context, arg db.IdempotencyParams) (*pb.CreateTransferResponse, error) {
+	result, found, err := idempotency.Replay(ctx, server.store, arg)
+	if err != nil {
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get idempotency key: %s", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	rsp, err := server.newCreateTransferResponse(ctx, result)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to format transfer: %s", err)
+	}
+	return rsp, nil
+}
+
+// newCreateTransferResponse formats the amounts of a transfer, its accounts and entries in their currencies
+func (server *Server) newCreateTransferResponse(ctx context.Context, result db.TransferTxResult) (*pb.CreateTransferResponse, error) {
+	fromCurrency, toCurrency := result.FromAccount.Currency, result.ToAccount.Currency
+
+	transfer, err := server.convertTransfer(ctx, result.Transfer, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	fromAccount, err := server.convertAccount(ctx, result.FromAccount)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := server.convertAccount(ctx, result.ToAccount)
+	if err != nil {
+		return nil, err
+	}
+	fromEntry, err := server.convertEntry(ctx, result.FromEntry, fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	toEntry, err := server.convertEntry(ctx, result.ToEntry, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer:    transfer,
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		FromEntry:   fromEntry,
+		ToEntry:     toEntry,
+	}
+	return rsp, nil
+}
+
+// validAccount fetches the account and checks that it holds the given currency
+func (server *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.existingAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s (expected: %s)", accountID, account.Currency, currency)
+	}
+	return account, nil
+}
+
+// existingAccount fetches the account, whatever its currency
+func (server *Server) existingAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	return account, nil
+}
+
+func validateCreateTransferRequest(ctx context.Context, currencies *currency.Registry, req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validator.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, util.CreateFieldViolation("from_account_id", err))
+	}
+	if err := validator.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, util.CreateFieldViolation("to_account_id", err))
+	}
+	if req.GetAmount() == "" {
+		violations = append(violations, util.CreateFieldViolation("amount", fmt.Errorf("must be a decimal amount")))
+	}
+	if err := validator.ValidateCurrency(ctx, currencies, req.GetCurrency()); err != nil {
+		violations = append(violations, util.CreateFieldViolation("currency", err))
+	}
+	if req.GetTotpCode() != "" {
+		if err := validator.ValidateTOTPCode(req.GetTotpCode()); err != nil {
+			violations = append(violations, util.CreateFieldViolation("totp_code", err))
+		}
+	}
+	return violations
+}
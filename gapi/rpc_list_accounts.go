@@ -0,0 +1,107 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pagination"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListAccountsRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	limit := pagination.PageSize(req.GetLimit())
+
+	if req.GetPage() > 0 {
+		arg := db.ListAccountsParams{
+			Owner:  authPayload.Username,
+			Limit:  limit,
+			Offset: (req.GetPage() - 1) * limit,
+		}
+
+		accounts, err := server.store.ListAccounts(ctx, arg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+		}
+
+		rspAccounts, err := server.convertAccounts(ctx, accounts)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to format accounts: %s", err)
+		}
+		return &pb.ListAccountsResponse{Accounts: rspAccounts}, nil
+	}
+
+	scope := "accounts:" + authPayload.Username
+	after, err := server.cursors.Decode(scope, req.GetCursor())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			util.CreateFieldViolation("cursor", err),
+		})
+	}
+
+	arg := db.ListAccountsAfterParams{
+		Owner:          authPayload.Username,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		PageSize:       limit + 1,
+	}
+
+	accounts, err := server.store.ListAccountsAfter(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	accounts, next := pagination.Trim(accounts, limit, func(account db.Account) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: account.CreatedAt, ID: account.ID}
+	})
+
+	rspAccounts, err := server.convertAccounts(ctx, accounts)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to format accounts: %s", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts:   rspAccounts,
+		NextCursor: server.cursors.Next(scope, next),
+	}
+	return rsp, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = append(violations, validatePagination(req.GetPage(), req.GetLimit(), req.GetCursor())...)
+	return violations
+}
+
+// validatePagination checks the paging fields shared by list requests:
+// either a page for offset paging, or an optional cursor
+func validatePagination(page int32, limit int32, cursor string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if page != 0 {
+		if err := validator.ValidatePage(page); err != nil {
+			violations = append(violations, util.CreateFieldViolation("page", err))
+		}
+		if cursor != "" {
+			violations = append(violations, util.CreateFieldViolation("cursor", errors.New("cannot be combined with page")))
+		}
+	}
+	if limit != 0 {
+		if err := validator.ValidateLimit(limit, 1, pagination.MaxPageSize); err != nil {
+			violations = append(violations, util.CreateFieldViolation("limit", err))
+		}
+	}
+	return violations
+}
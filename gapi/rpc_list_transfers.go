@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pagination"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListTransfersRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	// Ensure the user can only view their own transactions, unless they are support staff
+	if account.Owner != authPayload.Username && !util.CanViewAnyAccount(authPayload.Role) {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	// Every row involves the account checked above; the viewer filter guards the same rule in SQL
+	viewer := sql.NullString{String: authPayload.Username, Valid: !util.CanViewAnyAccount(authPayload.Role)}
+	limit := pagination.PageSize(req.GetLimit())
+
+	if req.GetPage() > 0 {
+		arg := db.ListTransfersParams{
+			FromAccountID: account.ID,
+			ToAccountID:   account.ID,
+			Viewer:        viewer,
+			Limit:         limit,
+			Offset:        (req.GetPage() - 1) * limit,
+		}
+
+		transfers, err := server.store.ListTransfers(ctx, arg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to list transfers: %s", err)
+		}
+
+		rsp := &pb.ListTransfersResponse{}
+		for _, transfer := range transfers {
+			rspTransfer, err := server.convertTransfer(ctx, transfer.Transfer, transfer.FromCurrency, transfer.ToCurrency)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to format transfer: %s", err)
+			}
+			rsp.Transfers = append(rsp.Transfers, rspTransfer)
+		}
+		return rsp, nil
+	}
+
+	scope := fmt.Sprintf("transfers:%d", account.ID)
+	after, err := server.cursors.Decode(scope, req.GetCursor())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			util.CreateFieldViolation("cursor", err),
+		})
+	}
+
+	arg := db.ListTransfersAfterParams{
+		FromAccountID:  account.ID,
+		ToAccountID:    account.ID,
+		Viewer:         viewer,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		PageSize:       limit + 1,
+	}
+
+	transfers, err := server.store.ListTransfersAfter(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfers: %s", err)
+	}
+
+	transfers, next := pagination.Trim(transfers, limit, func(transfer db.ListTransfersAfterRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: transfer.Transfer.CreatedAt, ID: transfer.Transfer.ID}
+	})
+
+	rsp := &pb.ListTransfersResponse{
+		NextCursor: server.cursors.Next(scope, next),
+	}
+	for _, transfer := range transfers {
+		rspTransfer, err := server.convertTransfer(ctx, transfer.Transfer, transfer.FromCurrency, transfer.ToCurrency)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to format transfer: %s", err)
+		}
+		rsp.Transfers = append(rsp.Transfers, rspTransfer)
+	}
+	return rsp, nil
+}
+
+func validateListTransfersRequest(req *pb.ListTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validator.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, util.CreateFieldViolation("account_id", err))
+	}
+	violations = append(violations, validatePagination(req.GetPage(), req.GetLimit(), req.GetCursor())...)
+	return violations
+}
@@ -0,0 +1,98 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/forabbie/vank-app/auth"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	violations := validator.ValidateLoginUserRequest(&db.LoginUserRequest{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+	})
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	mtdt := server.extractMetadata(ctx)
+	clientIP := peerIP(mtdt)
+
+	if err := server.allowLoginAttempt(ctx, req.GetUsername(), clientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, req.GetUsername())
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+	userExists := err == nil
+
+	// Unknown usernames and wrong passwords get the same answer, after the same amount of work
+	hashedPassword := util.DummyHashedPassword
+	if userExists {
+		hashedPassword = user.HashedPassword
+	}
+	if err := util.CheckPassword(req.GetPassword(), hashedPassword); err != nil || !userExists {
+		if err := server.loginThrottle.RecordFailure(ctx, req.GetUsername(), clientIP, userExists); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to record login attempt")
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "%s", auth.ErrInvalidCredentials)
+	}
+
+	if err := server.store.DeleteLoginFailures(ctx, user.Username); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reset login attempts")
+	}
+
+	// With two-factor authentication the password only earns a challenge, exchanged for tokens with VerifyLoginMfa
+	if user.IsMfaEnabled {
+		return server.createMfaChallenge(ctx, user)
+	}
+
+	return server.createLoginSession(ctx, user, mtdt)
+}
+
+// createLoginSession issues the access and refresh tokens of a successful login
+func (server *Server) createLoginSession(ctx context.Context, user db.User, mtdt *Metadata) (*pb.LoginUserResponse, error) {
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token")
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.RefreshTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token")
+	}
+
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    mtdt.UserAgent,
+		ClientIp:     mtdt.ClientIP,
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+		FamilyID:     refreshPayload.ID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create session")
+	}
+
+	rsp := &pb.LoginUserResponse{
+		User:                  convertUser(user),
+		SessionId:             session.ID.String(),
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+	}
+	return rsp, nil
+}
@@ -0,0 +1,89 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validator.ValidateUpdateUserRequest(&db.UpdateUserRequest{
+		ID:            req.GetId(),
+		Password:      req.Password,
+		FullName:      req.FullName,
+		Email:         req.Email,
+		KeepSessionID: req.KeepSessionId,
+	})
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	existingUser, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	if existingUser.ID != req.GetId() {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot update other user's info")
+	}
+
+	arg := db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			ID: req.GetId(),
+			FullName: sql.NullString{
+				String: req.GetFullName(),
+				Valid:  req.FullName != nil,
+			},
+			Email: sql.NullString{
+				String: req.GetEmail(),
+				Valid:  req.Email != nil,
+			},
+		},
+	}
+
+	if req.Password != nil {
+		hashedPassword, err := util.HashPassword(req.GetPassword())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+		}
+
+		arg.HashedPassword = sql.NullString{
+			String: hashedPassword,
+			Valid:  true,
+		}
+	}
+
+	// Changing the password logs the user out of every other session
+	if req.KeepSessionId != nil {
+		arg.KeepSessionID = uuid.NullUUID{
+			UUID:  uuid.MustParse(req.GetKeepSessionId()),
+			Valid: true,
+		}
+	}
+
+	result, err := server.store.UpdateUserTx(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, status.Errorf(codes.AlreadyExists, "username or email already exists")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err)
+	}
+
+	rsp := &pb.UpdateUserResponse{
+		User: convertUser(result.User),
+	}
+	return rsp, nil
+}
@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/forabbie/vank-app/auth"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/validator"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) VerifyLoginMfa(ctx context.Context, req *pb.VerifyLoginMfaRequest) (*pb.LoginUserResponse, error) {
+	violations := validator.ValidateVerifyLoginMfaRequest(&db.VerifyLoginMfaRequest{
+		MfaToken: req.GetMfaToken(),
+		Code:     req.GetCode(),
+	})
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	challenge, err := server.store.GetMfaChallenge(ctx, uuid.MustParse(req.GetMfaToken()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.Unauthenticated, "%s", auth.ErrInvalidMfaToken)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find mfa challenge")
+	}
+
+	if challenge.IsUsed || challenge.Attempts >= auth.MaxMfaChallengeAttempts || time.Now().After(challenge.ExpiresAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "%s", auth.ErrInvalidMfaToken)
+	}
+
+	// Wrong codes count as failed logins, so guessing them is throttled like guessing passwords
+	mtdt := server.extractMetadata(ctx)
+	clientIP := peerIP(mtdt)
+	if err := server.allowLoginAttempt(ctx, challenge.Username, clientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, challenge.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	valid, err := auth.CheckMfaCode(ctx, server.store, server.config.MfaEncryptionKey, user, req.GetCode(), true)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check two-factor code")
+	}
+	if !valid {
+		if _, err := server.store.FailMfaChallenge(ctx, challenge.ID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to record mfa attempt")
+		}
+		if err := server.loginThrottle.RecordFailure(ctx, user.Username, clientIP, true); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to record login attempt")
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "%s", auth.ErrInvalidMfaCode)
+	}
+
+	// A challenge is exchanged for a session only once, even by concurrent requests
+	if _, err := server.store.UseMfaChallenge(ctx, challenge.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.Unauthenticated, "%s", auth.ErrInvalidMfaToken)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to use mfa challenge")
+	}
+
+	return server.createLoginSession(ctx, user, mtdt)
+}
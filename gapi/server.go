@@ -0,0 +1,57 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/forabbie/vank-app/auth"
+	"github.com/forabbie/vank-app/currency"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/pagination"
+	"github.com/forabbie/vank-app/pb"
+	"github.com/forabbie/vank-app/token"
+	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
+)
+
+// Server serves gRPC requests for our banking service.
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config          util.Config
+	store           db.Store
+	tokenMaker      token.Maker
+	taskDistributor worker.TaskDistributor
+	currencies      *currency.Registry
+	cursors         *pagination.Signer
+	loginThrottle   *auth.LoginThrottle
+}
+
+// NewServer creates a new gRPC server
+func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	cursors, err := pagination.NewSigner(config.CursorSigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cursor signer: %w", err)
+	}
+
+	server := &Server{
+		config:          config,
+		store:           store,
+		tokenMaker:      tokenMaker,
+		taskDistributor: taskDistributor,
+		currencies:      currency.NewRegistry(store, config.CurrencyCacheDuration),
+		cursors:         cursors,
+		loginThrottle:   auth.NewLoginThrottle(config, store, taskDistributor),
+	}
+
+	// Amounts are formatted with the exponents of the currencies, so they must be known first
+	if err := server.currencies.Load(context.Background()); err != nil {
+		return nil, fmt.Errorf("cannot load currencies: %w", err)
+	}
+
+	return server, nil
+}
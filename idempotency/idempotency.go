@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+)
+
+const (
+	// Header is the HTTP header clients send the key in; over gRPC it is the idempotency-key metadata
+	Header       = "Idempotency-Key"
+	MaxKeyLength = 255
+)
+
+var (
+	ErrKeyTooLong = fmt.Errorf("idempotency key must not exceed %d characters", MaxKeyLength)
+	ErrKeyReused  = errors.New("idempotency key was already used for a different request")
+)
+
+// Transfer is the fingerprint of a transfer request, the same whichever API it was made through.
+// The two-factor code differs between retries of the same transfer, so it is left out,
+// and the amount is normalized since "5" and "5.00" are the same amount.
+type Transfer struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+// Params builds the idempotency parameters of a request sent with key.
+// It returns nil if the client did not send a key.
+func Params(key string, username string, fingerprint interface{}) (*db.IdempotencyParams, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+
+	if len(key) > MaxKeyLength {
+		return nil, ErrKeyTooLong
+	}
+
+	requestHash, err := hashRequest(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &db.IdempotencyParams{
+		Username:    username,
+		Key:         key,
+		RequestHash: requestHash,
+	}, nil
+}
+
+// hashRequest returns a fingerprint of the request,
+// used to detect an idempotency key being reused for a different request
+func hashRequest(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Replay returns the transfer recorded for an idempotency key, and false if the key has not been used yet.
+// It returns ErrKeyReused if the key was used for a different request.
+func Replay(ctx context.Context, store db.Querier, arg db.IdempotencyParams) (db.TransferTxResult, bool, error) {
+	var result db.TransferTxResult
+
+	record, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.Key,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, false, nil
+		}
+		return result, false, err
+	}
+
+	if record.RequestHash != arg.RequestHash {
+		return result, false, ErrKeyReused
+	}
+
+	// The result is recorded as the store returned it, so it is rendered like a fresh one
+	if err := json.Unmarshal(record.Response, &result); err != nil {
+		return result, false, err
+	}
+	return result, true, nil
+}
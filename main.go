@@ -1,13 +1,28 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"net"
+	"net/http"
+	"strings"
 
 	"github.com/forabbie/vank-app/api"
 	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/doc"
+	"github.com/forabbie/vank-app/fx"
+	"github.com/forabbie/vank-app/gapi"
+	"github.com/forabbie/vank-app/idempotency"
+	"github.com/forabbie/vank-app/mail"
+	"github.com/forabbie/vank-app/pb"
 	"github.com/forabbie/vank-app/util"
+	"github.com/forabbie/vank-app/worker"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 func main() {
@@ -22,7 +37,102 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+
+	mailer := mail.NewGmailSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailSenderPassword)
+	taskProcessor := worker.NewPostgresTaskProcessor(config, store, mailer)
+	err = taskProcessor.Start()
+	if err != nil {
+		log.Fatal("cannot start task processor:", err)
+	}
+	defer taskProcessor.Shutdown()
+
+	// Without a rates file, rates are expected to be loaded into the fx_rates table by other means
+	if config.FxRatesFile != "" {
+		provider := fx.NewStaticFileProvider(config.FxRatesFile)
+		go fx.RunRefresher(context.Background(), store, provider, config.FxRefreshInterval)
+	}
+
+	taskDistributor := worker.NewPostgresTaskDistributor()
+	go runGatewayServer(config, store, taskDistributor)
+	go runGrpcServer(config, store, taskDistributor)
+	runGinServer(config, store, taskDistributor)
+}
+
+func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
+	server, err := gapi.NewServer(config, store, taskDistributor)
+	if err != nil {
+		log.Fatal("cannot create gRPC server:", err)
+	}
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal("cannot create listener:", err)
+	}
+
+	log.Printf("start gRPC server at %s", listener.Addr().String())
+	err = grpcServer.Serve(listener)
+	if err != nil {
+		log.Fatal("cannot start gRPC server:", err)
+	}
+}
+
+func runGatewayServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
+	server, err := gapi.NewServer(config, store, taskDistributor)
+	if err != nil {
+		log.Fatal("cannot create gRPC server:", err)
+	}
+
+	// Keep the snake_case field names of the proto files, like the Gin API
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+
+	// Forward the Idempotency-Key header to the metadata read by CreateTransfer
+	headerMatcher := runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if http.CanonicalHeaderKey(key) == idempotency.Header {
+			return strings.ToLower(idempotency.Header), true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	})
+
+	grpcMux := runtime.NewServeMux(jsonOption, headerMatcher)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
+	if err != nil {
+		log.Fatal("cannot register handler server:", err)
+	}
+
+	// The gateway is a surface of its own, generated from the protos, apart from the /api/v1 routes
+	mux := http.NewServeMux()
+	mux.Handle("/gateway/", grpcMux)
+	mux.Handle("/swagger/", http.FileServer(http.FS(doc.Swagger)))
+
+	listener, err := net.Listen("tcp", config.GatewayServerAddress)
+	if err != nil {
+		log.Fatal("cannot create listener:", err)
+	}
+
+	log.Printf("start HTTP gateway server at %s", listener.Addr().String())
+	err = http.Serve(listener, mux)
+	if err != nil {
+		log.Fatal("cannot start HTTP gateway server:", err)
+	}
+}
+
+func runGinServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
+	server, err := api.NewServer(config, store, taskDistributor)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
@@ -0,0 +1,170 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Different types of error returned by the money functions
+var (
+	// ErrInvalidAmount is returned when a string is not a decimal amount of the currency
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrOverflow is returned when an amount does not fit in an int64 of minor units
+	ErrOverflow = errors.New("amount out of range")
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// Amount is an amount of money in the minor units of its currency, e.g. cents for USD
+type Amount struct {
+	Value    int64
+	Currency string
+	// Exponent is the number of digits after the decimal separator of the currency
+	Exponent int32
+}
+
+// New creates an amount from a value in minor units of a currency with the given exponent
+func New(value int64, currency string, exponent int32) Amount {
+	return Amount{
+		Value:    value,
+		Currency: currency,
+		Exponent: exponent,
+	}
+}
+
+// Parse parses a decimal string such as "12.34" into an amount of the currency.
+// The string may not have more digits after the decimal separator than the exponent allows.
+func Parse(s string, currency string, exponent int32) (Amount, error) {
+	digits, negative := strings.CutPrefix(s, "-")
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) {
+		return Amount{}, fmt.Errorf("%w %q: must be a decimal number", ErrInvalidAmount, s)
+	}
+	if len(fraction) > int(exponent) {
+		return Amount{}, fmt.Errorf("%w %q: %s has %d digits after the decimal separator", ErrInvalidAmount, s, currency, exponent)
+	}
+
+	var value int64
+	for _, digit := range whole + fraction + strings.Repeat("0", int(exponent)-len(fraction)) {
+		d := int64(digit - '0')
+		if value > (math.MaxInt64-d)/10 {
+			return Amount{}, fmt.Errorf("%w: %s", ErrOverflow, s)
+		}
+		value = value*10 + d
+	}
+
+	if negative {
+		value = -value
+	}
+	return New(value, currency, exponent), nil
+}
+
+func isDigits(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount as a decimal string with all the digits of its currency, e.g. "12.30"
+func (amount Amount) String() string {
+	exponent := int(amount.Exponent)
+
+	// Go through uint64 so that the smallest int64 can be negated
+	magnitude := uint64(amount.Value)
+	sign := ""
+	if amount.Value < 0 {
+		magnitude = -magnitude
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Sign returns -1, 0 or 1 depending on whether the amount is negative, zero or positive
+func (amount Amount) Sign() int {
+	switch {
+	case amount.Value < 0:
+		return -1
+	case amount.Value > 0:
+		return 1
+	}
+	return 0
+}
+
+// Add returns the sum of two amounts of the same currency
+func (amount Amount) Add(other Amount) (Amount, error) {
+	if amount.Currency != other.Currency || amount.Exponent != other.Exponent {
+		return Amount{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, amount.Currency, other.Currency)
+	}
+	if (other.Value > 0 && amount.Value > math.MaxInt64-other.Value) ||
+		(other.Value < 0 && amount.Value < math.MinInt64-other.Value) {
+		return Amount{}, ErrOverflow
+	}
+	return New(amount.Value+other.Value, amount.Currency, amount.Exponent), nil
+}
+
+// Sub returns the difference of two amounts of the same currency
+func (amount Amount) Sub(other Amount) (Amount, error) {
+	negated, err := other.Neg()
+	if err != nil {
+		return Amount{}, err
+	}
+	return amount.Add(negated)
+}
+
+// Neg returns the opposite of the amount
+func (amount Amount) Neg() (Amount, error) {
+	if amount.Value == math.MinInt64 {
+		return Amount{}, ErrOverflow
+	}
+	return New(-amount.Value, amount.Currency, amount.Exponent), nil
+}
+
+type jsonAmount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string together with its currency,
+// so that clients never have to know the exponent of the currency
+func (amount Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAmount{
+		Value:    amount.String(),
+		Currency: amount.Currency,
+	})
+}
+
+// UnmarshalJSON decodes an amount encoded by MarshalJSON. The value is written with
+// all the digits of its currency, so the exponent is the number of digits after the point.
+func (amount *Amount) UnmarshalJSON(data []byte) error {
+	var decoded jsonAmount
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	_, fraction, _ := strings.Cut(decoded.Value, ".")
+	parsed, err := Parse(decoded.Value, decoded.Currency, int32(len(fraction)))
+	if err != nil {
+		return err
+	}
+
+	*amount = parsed
+	return nil
+}
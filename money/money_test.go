@@ -0,0 +1,113 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	exponents := map[string]int32{"USD": 2, "JPY": 0, "KWD": 3}
+
+	testCases := []struct {
+		input    string
+		currency string
+		expected int64
+		err      error
+	}{
+		{input: "12.34", currency: "USD", expected: 1234},
+		{input: "12.3", currency: "USD", expected: 1230},
+		{input: "12", currency: "USD", expected: 1200},
+		{input: "0.01", currency: "USD", expected: 1},
+		{input: "-5.50", currency: "USD", expected: -550},
+		{input: "1500", currency: "JPY", expected: 1500},
+		{input: "1.005", currency: "KWD", expected: 1005},
+		{input: "92233720368547758.07", currency: "USD", expected: math.MaxInt64},
+		{input: "12.345", currency: "USD", err: ErrInvalidAmount},
+		{input: "1.5", currency: "JPY", err: ErrInvalidAmount},
+		{input: "", currency: "USD", err: ErrInvalidAmount},
+		{input: ".5", currency: "USD", err: ErrInvalidAmount},
+		{input: "5.", currency: "USD", err: ErrInvalidAmount},
+		{input: "+5", currency: "USD", err: ErrInvalidAmount},
+		{input: "1e3", currency: "USD", err: ErrInvalidAmount},
+		{input: "1,000.00", currency: "USD", err: ErrInvalidAmount},
+		{input: "92233720368547758.08", currency: "USD", err: ErrOverflow},
+	}
+
+	for _, tc := range testCases {
+		amount, err := Parse(tc.input, tc.currency, exponents[tc.currency])
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		require.Equal(t, New(tc.expected, tc.currency, exponents[tc.currency]), amount, tc.input)
+	}
+}
+
+func TestString(t *testing.T) {
+	require.Equal(t, "12.34", New(1234, "USD", 2).String())
+	require.Equal(t, "0.05", New(5, "USD", 2).String())
+	require.Equal(t, "0.00", New(0, "USD", 2).String())
+	require.Equal(t, "-0.05", New(-5, "USD", 2).String())
+	require.Equal(t, "1500", New(1500, "JPY", 0).String())
+	require.Equal(t, "1.005", New(1005, "KWD", 3).String())
+	require.Equal(t, "-92233720368547758.08", New(math.MinInt64, "USD", 2).String())
+
+	// formatting and parsing round-trip
+	for _, value := range []int64{0, 1, -1, 99, 100, -12345, math.MaxInt64} {
+		amount := New(value, "USD", 2)
+		parsed, err := Parse(amount.String(), "USD", 2)
+		require.NoError(t, err)
+		require.Equal(t, amount, parsed)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	sum, err := New(150, "USD", 2).Add(New(-200, "USD", 2))
+	require.NoError(t, err)
+	require.Equal(t, New(-50, "USD", 2), sum)
+	require.Equal(t, -1, sum.Sign())
+
+	difference, err := New(150, "USD", 2).Sub(New(150, "USD", 2))
+	require.NoError(t, err)
+	require.Equal(t, 0, difference.Sign())
+
+	_, err = New(1, "USD", 2).Add(New(1, "EUR", 2))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = New(1, "USD", 2).Add(New(1, "USD", 0))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, "USD", 2).Add(New(1, "USD", 2))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, "USD", 2).Add(New(-1, "USD", 2))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(0, "USD", 2).Sub(New(math.MinInt64, "USD", 2))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, "USD", 2).Neg()
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestJSON(t *testing.T) {
+	amount := New(-1234, "EUR", 2)
+
+	data, err := json.Marshal(amount)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"value":"-12.34","currency":"EUR"}`, string(data))
+
+	var decoded Amount
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, amount, decoded)
+
+	// the exponent is taken from the digits of the value
+	require.NoError(t, json.Unmarshal([]byte(`{"value":"1500","currency":"JPY"}`), &decoded))
+	require.Equal(t, New(1500, "JPY", 0), decoded)
+
+	err = json.Unmarshal([]byte(`{"value":"1.2.3","currency":"EUR"}`), &decoded)
+	require.ErrorIs(t, err, ErrInvalidAmount)
+}
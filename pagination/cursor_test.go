@@ -0,0 +1,97 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSigner(t *testing.T) *Signer {
+	signer, err := NewSigner(util.RandomString(32))
+	require.NoError(t, err)
+	return signer
+}
+
+func TestNewSignerEmptyKey(t *testing.T) {
+	signer, err := NewSigner("")
+	require.ErrorIs(t, err, ErrEmptySigningKey)
+	require.Nil(t, signer)
+}
+
+func TestCursor(t *testing.T) {
+	signer := newTestSigner(t)
+
+	cursor := Cursor{
+		CreatedAt: time.Date(2026, 1, 12, 9, 30, 0, 123456000, time.UTC),
+		ID:        util.RandomInt(1, 1000),
+	}
+
+	token := signer.Encode("accounts:alice", cursor)
+	decoded, err := signer.Decode("accounts:alice", token)
+	require.NoError(t, err)
+	require.Equal(t, cursor, decoded)
+
+	// the first page has no cursor, and the last page no next cursor
+	decoded, err = signer.Decode("accounts:alice", "")
+	require.NoError(t, err)
+	require.Zero(t, decoded)
+	require.Empty(t, signer.Next("accounts:alice", nil))
+	require.Equal(t, token, signer.Next("accounts:alice", &cursor))
+}
+
+func TestCursorInvalid(t *testing.T) {
+	signer := newTestSigner(t)
+	cursor := Cursor{CreatedAt: time.Now().UTC(), ID: 42}
+	token := signer.Encode("accounts:alice", cursor)
+
+	payload, signature, _ := strings.Cut(token, ".")
+	forged := signer.Encode("accounts:alice", Cursor{CreatedAt: cursor.CreatedAt, ID: 1})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	testCases := []struct {
+		name   string
+		signer *Signer
+		scope  string
+		token  string
+	}{
+		{name: "OtherScope", signer: signer, scope: "accounts:bob", token: token},
+		{name: "OtherKey", signer: newTestSigner(t), scope: "accounts:alice", token: token},
+		{name: "TamperedPayload", signer: signer, scope: "accounts:alice", token: forgedPayload + "." + signature},
+		{name: "TruncatedSignature", signer: signer, scope: "accounts:alice", token: payload + "." + signature[:10]},
+		{name: "MissingSignature", signer: signer, scope: "accounts:alice", token: payload},
+		{name: "NotBase64", signer: signer, scope: "accounts:alice", token: "!!." + signature},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.signer.Decode(tc.scope, tc.token)
+			require.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	position := func(id int64) Cursor { return Cursor{ID: id} }
+
+	// one row past the page means there is a next page
+	rows, next := Trim([]int64{1, 2, 3, 4}, 3, position)
+	require.Equal(t, []int64{1, 2, 3}, rows)
+	require.Equal(t, &Cursor{ID: 3}, next)
+
+	rows, next = Trim([]int64{1, 2, 3}, 3, position)
+	require.Equal(t, []int64{1, 2, 3}, rows)
+	require.Nil(t, next)
+
+	rows, next = Trim([]int64{}, 3, position)
+	require.Empty(t, rows)
+	require.Nil(t, next)
+}
+
+func TestPageSize(t *testing.T) {
+	require.Equal(t, int32(DefaultPageSize), PageSize(0))
+	require.Equal(t, int32(25), PageSize(25))
+}
@@ -0,0 +1,21 @@
+package pagination
+
+// PageSize returns the page size to read for a requested size, where 0 means the default
+func PageSize(size int32) int32 {
+	if size <= 0 {
+		return DefaultPageSize
+	}
+	return size
+}
+
+// Trim cuts rows, read with a limit of size+1, down to a page of size rows.
+// It returns the cursor of the next page, or nil when there are no more rows.
+func Trim[T any](rows []T, size int32, position func(T) Cursor) ([]T, *Cursor) {
+	if int32(len(rows)) <= size {
+		return rows, nil
+	}
+
+	rows = rows[:size]
+	next := position(rows[size-1])
+	return rows, &next
+}
@@ -0,0 +1,47 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"date", "entry_id", "transfer_id", "counterparty_account_id", "description", "amount", "balance", "currency"}
+
+// WriteCSV writes the statement as CSV, one row per entry between an opening and a closing balance row
+func (statement Statement) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	currency := statement.Account.Currency
+
+	records := [][]string{
+		csvHeader,
+		{statement.Period.From.Format(DateFormat), "", "", "", "Opening balance", "", statement.OpeningBalance.String(), currency},
+	}
+
+	for _, line := range statement.Lines {
+		records = append(records, []string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(line.EntryID, 10),
+			formatID(line.TransferID),
+			formatID(line.CounterpartyAccountID),
+			line.Description,
+			line.Amount.String(),
+			line.Balance.String(),
+			currency,
+		})
+	}
+
+	records = append(records, []string{
+		statement.Period.To.Format(DateFormat), "", "", "", "Closing balance", "", statement.ClosingBalance.String(), currency,
+	})
+
+	return writer.WriteAll(records)
+}
+
+func formatID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
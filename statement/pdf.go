@@ -0,0 +1,91 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Widths of the columns of the entries table, in millimeters
+var pdfColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{title: "Date", width: 38, align: "L"},
+	{title: "Description", width: 82, align: "L"},
+	{title: "Amount", width: 30, align: "R"},
+	{title: "Balance", width: 30, align: "R"},
+}
+
+// WritePDF writes the statement as an A4 PDF document
+func (statement Statement) WritePDF(w io.Writer) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(statement.Filename(FormatPDF), true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AliasNbPages("")
+	pdf.AddPage()
+
+	// Core fonts only cover cp1252, owner names may not be ASCII
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Account Statement", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	currency := statement.Account.Currency
+	details := []string{
+		fmt.Sprintf("Account: #%d (%s)", statement.Account.ID, currency),
+		fmt.Sprintf("Owner: %s", statement.Account.Owner),
+		fmt.Sprintf("Period: %s to %s", statement.Period.From.Format(DateFormat), statement.Period.To.Format(DateFormat)),
+		fmt.Sprintf("Opening balance: %s %s", statement.OpeningBalance, currency),
+		fmt.Sprintf("Closing balance: %s %s", statement.ClosingBalance, currency),
+	}
+	for _, detail := range details {
+		pdf.CellFormat(0, 6, translate(detail), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	writeHeader := func() {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetFillColor(230, 230, 230)
+		for _, column := range pdfColumns {
+			pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	writeHeader()
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	for _, line := range statement.Lines {
+		// Break pages before the automatic page break does, to repeat the header
+		if pdf.GetY()+6 > pageHeight-bottomMargin {
+			pdf.AddPage()
+			writeHeader()
+		}
+
+		cells := []string{
+			line.CreatedAt.UTC().Format("2006-01-02 15:04"),
+			translate(line.Description),
+			line.Amount.String(),
+			line.Balance.String(),
+		}
+		for i, column := range pdfColumns {
+			pdf.CellFormat(column.width, 6, cells[i], "1", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if len(statement.Lines) == 0 {
+		pdf.CellFormat(0, 6, "No entries in this period.", "", 1, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,170 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/forabbie/vank-app/currency"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/money"
+)
+
+const (
+	// DateFormat is how the days of a period are written, in requests and in files
+	DateFormat = "2006-01-02"
+	// MaxPeriod bounds how many entries a single statement reads
+	MaxPeriod = 366 * 24 * time.Hour
+)
+
+// Formats a statement can be rendered in
+const (
+	FormatCSV = "csv"
+	FormatPDF = "pdf"
+)
+
+// Period is a range of whole days in UTC, both included
+type Period struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// End returns the start of the day after the period, for queries with an exclusive upper bound
+func (period Period) End() time.Time {
+	return period.To.AddDate(0, 0, 1)
+}
+
+// Validate checks that the period is not empty and not longer than MaxPeriod
+func (period Period) Validate() error {
+	if !period.From.Before(period.End()) {
+		return errors.New("to must not be before from")
+	}
+	if period.End().Sub(period.From) > MaxPeriod {
+		return errors.New("statement period must not exceed one year")
+	}
+	return nil
+}
+
+// Statement is the statement of an account over a period, ready to be rendered
+type Statement struct {
+	Account        db.Account
+	Period         Period
+	OpeningBalance money.Amount
+	ClosingBalance money.Amount
+	Lines          []Line
+}
+
+// Line is an entry of a statement
+type Line struct {
+	EntryID               int64
+	TransferID            int64
+	CounterpartyAccountID int64
+	Description           string
+	Amount                money.Amount
+	// Balance is the balance of the account right after the entry
+	Balance   money.Amount
+	CreatedAt time.Time
+}
+
+// New builds a statement from the entries read by the store, formatting
+// amounts with the exponents of the currencies in the registry
+func New(ctx context.Context, currencies *currency.Registry, result db.StatementTxResult, period Period) (Statement, error) {
+	code := result.Account.Currency
+	exponent, err := currencies.Exponent(ctx, code)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	statement := Statement{
+		Account:        result.Account,
+		Period:         period,
+		OpeningBalance: money.New(result.OpeningBalance, code, exponent),
+		ClosingBalance: money.New(result.ClosingBalance, code, exponent),
+		Lines:          make([]Line, len(result.Lines)),
+	}
+
+	for i, entry := range result.Lines {
+		description, err := describe(ctx, currencies, result.Account.ID, entry)
+		if err != nil {
+			return Statement{}, err
+		}
+
+		line := Line{
+			EntryID:               entry.Entry.ID,
+			CounterpartyAccountID: entry.CounterpartyAccountID,
+			Description:           description,
+			Amount:                money.New(entry.Entry.Amount, code, exponent),
+			Balance:               money.New(entry.Balance, code, exponent),
+			CreatedAt:             entry.Entry.CreatedAt,
+		}
+		if entry.Transfer != nil {
+			line.TransferID = entry.Transfer.Transfer.ID
+		}
+		statement.Lines[i] = line
+	}
+
+	return statement, nil
+}
+
+func describe(ctx context.Context, currencies *currency.Registry, accountID int64, line db.StatementLine) (string, error) {
+	if line.Transfer == nil {
+		return "Entry", nil
+	}
+
+	transfer := line.Transfer.Transfer
+	if transfer.FromAccountID == accountID {
+		description := fmt.Sprintf("Transfer to account #%d", transfer.ToAccountID)
+		if line.Transfer.FromCurrency != line.Transfer.ToCurrency {
+			toAmount, err := currencies.Amount(ctx, transfer.ToAmount, line.Transfer.ToCurrency)
+			if err != nil {
+				return "", err
+			}
+			description += fmt.Sprintf(" (%s %s at %s)", toAmount, toAmount.Currency, transfer.FxRate)
+		}
+		return description, nil
+	}
+
+	description := fmt.Sprintf("Transfer from account #%d", transfer.FromAccountID)
+	if line.Transfer.FromCurrency != line.Transfer.ToCurrency {
+		amount, err := currencies.Amount(ctx, transfer.Amount, line.Transfer.FromCurrency)
+		if err != nil {
+			return "", err
+		}
+		description += fmt.Sprintf(" (%s %s at %s)", amount, amount.Currency, transfer.FxRate)
+	}
+	return description, nil
+}
+
+// Filename returns the name of the statement file in the given format
+func (statement Statement) Filename(format string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s",
+		statement.Account.ID,
+		statement.Period.From.Format(DateFormat),
+		statement.Period.To.Format(DateFormat),
+		format,
+	)
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+// Render writes the statement to w in the given format
+func (statement Statement) Render(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return statement.WriteCSV(w)
+	case FormatPDF:
+		return statement.WritePDF(w)
+	}
+	return fmt.Errorf("unsupported statement format %q", format)
+}
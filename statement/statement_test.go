@@ -0,0 +1,138 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/currency"
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement(t *testing.T) Statement {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return([]db.Currency{
+			{Code: "USD", Exponent: 2, IsEnabled: true},
+			{Code: "JPY", Exponent: 0, IsEnabled: true},
+		}, nil)
+	currencies := currency.NewRegistry(store, time.Minute)
+
+	account := db.Account{ID: 1, Owner: "alice", Balance: 9960, Currency: "USD"}
+	createdAt := time.Date(2026, 1, 12, 9, 30, 0, 0, time.UTC)
+
+	outgoing := db.ListTransfersByIDsRow{
+		Transfer:     db.Transfer{ID: 10, FromAccountID: 1, ToAccountID: 2, Amount: 1050, ToAmount: 1589, FxRate: "151.36"},
+		FromCurrency: "USD",
+		ToCurrency:   "JPY",
+	}
+	incoming := db.ListTransfersByIDsRow{
+		Transfer:     db.Transfer{ID: 11, FromAccountID: 3, ToAccountID: 1, Amount: 1010, ToAmount: 1010, FxRate: "1"},
+		FromCurrency: "USD",
+		ToCurrency:   "USD",
+	}
+
+	result := db.StatementTxResult{
+		Account:        account,
+		OpeningBalance: 10000,
+		ClosingBalance: 9960,
+		Lines: []db.StatementLine{
+			{
+				Entry:                 db.Entry{ID: 100, AccountID: 1, Amount: -1050, CreatedAt: createdAt},
+				Transfer:              &outgoing,
+				CounterpartyAccountID: 2,
+				Balance:               8950,
+			},
+			{
+				Entry:                 db.Entry{ID: 101, AccountID: 1, Amount: 1010, CreatedAt: createdAt.Add(time.Hour)},
+				Transfer:              &incoming,
+				CounterpartyAccountID: 3,
+				Balance:               9960,
+			},
+		},
+	}
+
+	statement, err := New(context.Background(), currencies, result, Period{
+		From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	return statement
+}
+
+func TestNew(t *testing.T) {
+	statement := testStatement(t)
+
+	require.Equal(t, "100.00", statement.OpeningBalance.String())
+	require.Equal(t, "99.60", statement.ClosingBalance.String())
+	require.Len(t, statement.Lines, 2)
+
+	// the counterparty amount is formatted with the exponent of its own currency
+	require.Equal(t, "Transfer to account #2 (1589 JPY at 151.36)", statement.Lines[0].Description)
+	require.Equal(t, int64(10), statement.Lines[0].TransferID)
+	require.Equal(t, "-10.50", statement.Lines[0].Amount.String())
+	require.Equal(t, "89.50", statement.Lines[0].Balance.String())
+
+	require.Equal(t, "Transfer from account #3", statement.Lines[1].Description)
+
+	require.Equal(t, "statement-1-2026-01-01-2026-01-31.pdf", statement.Filename(FormatPDF))
+}
+
+func TestNewUnknownCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return([]db.Currency{{Code: "USD", Exponent: 2, IsEnabled: true}}, nil)
+	currencies := currency.NewRegistry(store, time.Minute)
+
+	result := db.StatementTxResult{Account: db.Account{ID: 1, Currency: "JPY"}}
+	_, err := New(context.Background(), currencies, result, Period{})
+	require.ErrorIs(t, err, currency.ErrUnknownCurrency)
+}
+
+func TestPeriodValidate(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, Period{From: day, To: day}.Validate())
+	require.NoError(t, Period{From: day, To: day.AddDate(0, 0, 365)}.Validate())
+	require.Error(t, Period{From: day, To: day.AddDate(0, 0, -1)}.Validate())
+	require.Error(t, Period{From: day, To: day.AddDate(0, 0, 366)}.Validate())
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testStatement(t).Render(&buf, FormatCSV))
+
+	expected := "date,entry_id,transfer_id,counterparty_account_id,description,amount,balance,currency\n" +
+		"2026-01-01,,,,Opening balance,,100.00,USD\n" +
+		"2026-01-12T09:30:00Z,100,10,2,Transfer to account #2 (1589 JPY at 151.36),-10.50,89.50,USD\n" +
+		"2026-01-12T10:30:00Z,101,11,3,Transfer from account #3,10.10,99.60,USD\n" +
+		"2026-01-31,,,,Closing balance,,99.60,USD\n"
+	require.Equal(t, expected, buf.String())
+}
+
+func TestWritePDF(t *testing.T) {
+	statement := testStatement(t)
+
+	// enough entries to span several pages
+	line := statement.Lines[0]
+	for i := 0; i < 100; i++ {
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, statement.Render(&buf, FormatPDF))
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	require.True(t, bytes.Contains(buf.Bytes(), []byte("/Count 3")))
+
+	require.Error(t, statement.Render(&buf, "xlsx"))
+}
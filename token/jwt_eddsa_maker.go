@@ -0,0 +1,79 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// JWTEdDSAMaker signs JWT tokens with an Ed25519 private key,
+// so they can be verified by anyone holding the public key
+type JWTEdDSAMaker struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+	keyID      string
+}
+
+// NewJWTEdDSAMaker creates a new JWT maker that signs and verifies tokens with an Ed25519 key pair
+func NewJWTEdDSAMaker(privateKey ed25519.PrivateKey) (Maker, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	return &JWTEdDSAMaker{privateKey: privateKey, publicKey: publicKey}, nil
+}
+
+// NewJWTEdDSAVerifier creates a JWT maker that can only verify tokens, using an Ed25519 public key
+func NewJWTEdDSAVerifier(publicKey ed25519.PublicKey) (Maker, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return &JWTEdDSAMaker{publicKey: publicKey}, nil
+}
+
+// CreateToken generates a new JWT token for a specific username, role and duration
+func (maker *JWTEdDSAMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	if maker.privateKey == nil {
+		return "", nil, ErrMissingPrivateKey
+	}
+
+	payload, err := NewPayload(username, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload)
+	if maker.keyID != "" {
+		jwtToken.Header[keyIDHeader] = maker.keyID
+	}
+	token, err := jwtToken.SignedString(maker.privateKey)
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *JWTEdDSAMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodEd25519)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return maker.publicKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,103 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func randomEd25519Key(t *testing.T) ed25519.PrivateKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func TestJWTEdDSAMaker(t *testing.T) {
+	privateKey := randomEd25519Key(t)
+	maker, err := NewJWTEdDSAMaker(privateKey)
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	role := util.DepositorRole
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, role, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	// anyone holding the public key can verify the token
+	verifier, err := NewJWTEdDSAVerifier(privateKey.Public().(ed25519.PublicKey))
+	require.NoError(t, err)
+
+	payload, err = verifier.VerifyToken(token)
+	require.NoError(t, err)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+
+	// but cannot create one
+	_, _, err = verifier.CreateToken(username, role, duration)
+	require.ErrorIs(t, err, ErrMissingPrivateKey)
+}
+
+func TestExpiredJWTEdDSAToken(t *testing.T) {
+	maker, err := NewJWTEdDSAMaker(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTEdDSATokenWrongKey(t *testing.T) {
+	maker, err := NewJWTEdDSAMaker(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	otherMaker, err := NewJWTEdDSAMaker(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	payload, err := otherMaker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidJWTEdDSATokenHS256(t *testing.T) {
+	privateKey := randomEd25519Key(t)
+	maker, err := NewJWTEdDSAMaker(privateKey)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	// a token signed with HS256 using the public key as secret must not be accepted
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	token, err := jwtToken.SignedString([]byte(privateKey.Public().(ed25519.PublicKey)))
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
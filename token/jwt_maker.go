@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 )
 
 const minSecretKeySize = 32
 
 type JWTMaker struct {
 	secretKey string
+	keyID     string
 }
 
 // NewJWTMaker creates a new JWT maker
@@ -19,16 +20,19 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	if len(secretKey) < minSecretKeySize {
 		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
 	}
-	return &JWTMaker{secretKey}, nil
+	return &JWTMaker{secretKey: secretKey}, nil
 }
 
-// CreateToken generates a new JWT token for a specific username and duration
-func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+// CreateToken generates a new JWT token for a specific username, role and duration
+func (maker *JWTMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration)
 	if err != nil {
 		return "", payload, err
 	}
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	if maker.keyID != "" {
+		jwtToken.Header[keyIDHeader] = maker.keyID
+	}
 	token, err := jwtToken.SignedString([]byte(maker.secretKey))
 	return token, payload, err
 }
@@ -0,0 +1,43 @@
+package token
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/o1egl/paseto"
+)
+
+// keyIDHeader is the JWT header holding the ID of the signing key
+const keyIDHeader = "kid"
+
+// footer is the unencrypted but authenticated part of a PASETO token holding the ID of the key
+type footer struct {
+	KeyID string `json:"kid"`
+}
+
+// newFooter returns the PASETO footer for a key ID, or nil when the maker has no key ID
+func newFooter(keyID string) interface{} {
+	if keyID == "" {
+		return nil
+	}
+	return &footer{KeyID: keyID}
+}
+
+// tokenKeyID reads the key ID stamped on a token without verifying it
+func tokenKeyID(token string) (string, error) {
+	if strings.HasPrefix(token, "v2.") {
+		var f footer
+		// tokens made without a key ID have no footer, which leaves the ID empty
+		if err := paseto.ParseFooter(token, &f); err != nil {
+			return "", ErrInvalidToken
+		}
+		return f.KeyID, nil
+	}
+
+	jwtToken, _, err := jwt.NewParser().ParseUnverified(token, &Payload{})
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	keyID, _ := jwtToken.Header[keyIDHeader].(string)
+	return keyID, nil
+}
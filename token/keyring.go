@@ -0,0 +1,66 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Key is one signing key of a keyring
+type Key struct {
+	// ID is stamped on every token made with the key as its kid
+	ID string `json:"id"`
+	// Key is the secret of the key: the symmetric key, or the hex encoded Ed25519 seed
+	Key string `json:"key"`
+	// PublicKey is the hex encoded Ed25519 public key, for services that only verify tokens
+	PublicKey string `json:"public_key,omitempty"`
+	// ExpiresAt is when a retired key stops being accepted, zero means never
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoadKeyringFile reads a keyring from a JSON file holding a list of keys, current key first
+func LoadKeyringFile(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read keyring file: %w", err)
+	}
+
+	var keys []Key
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("cannot parse keyring file: %w", err)
+	}
+
+	return keys, nil
+}
+
+// ParseKeyList parses a keyring from a comma separated list of id:key[:expires_at] entries, current key first.
+// expires_at is in RFC 3339 format.
+func ParseKeyList(list string) ([]Key, error) {
+	var keys []Key
+
+	for _, entry := range strings.Split(list, ",") {
+		fields := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid keyring entry %q: must be id:key[:expires_at]", entry)
+		}
+
+		key := Key{
+			ID:  fields[0],
+			Key: fields[1],
+		}
+
+		if len(fields) == 3 {
+			expiresAt, err := time.Parse(time.RFC3339, fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid expiry of key %s: %w", key.ID, err)
+			}
+			key.ExpiresAt = expiresAt
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
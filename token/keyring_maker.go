@@ -0,0 +1,107 @@
+package token
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeyringMaker makes tokens with the current key of a keyring
+// and verifies them with whichever key their kid points to,
+// so rotating the key doesn't invalidate the tokens made with the previous ones
+type KeyringMaker struct {
+	current   Maker
+	makers    map[string]Maker
+	expiresAt map[string]time.Time
+}
+
+// NewKeyringMaker creates a new keyring maker of the given token type.
+// The first key is the current one, the others are only used to verify tokens until they expire.
+// A previous key without an ID verifies the tokens made before the keyring was introduced, which carry no kid.
+func NewKeyringMaker(tokenType string, keys []Key) (Maker, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keyring must have at least one key")
+	}
+
+	maker := &KeyringMaker{
+		makers:    make(map[string]Maker),
+		expiresAt: make(map[string]time.Time),
+	}
+
+	if keys[0].ID == "" {
+		return nil, fmt.Errorf("current keyring key must have an id")
+	}
+
+	for _, key := range keys {
+		if _, ok := maker.makers[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate keyring key id: %s", key.ID)
+		}
+
+		keyMaker, err := newKeyMaker(tokenType, key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create maker for key %s: %w", key.ID, err)
+		}
+
+		maker.makers[key.ID] = keyMaker
+		maker.expiresAt[key.ID] = key.ExpiresAt
+	}
+
+	maker.current = maker.makers[keys[0].ID]
+	return maker, nil
+}
+
+// newKeyMaker creates the maker of a single keyring key, stamping the key ID on its tokens
+func newKeyMaker(tokenType string, key Key) (Maker, error) {
+	var maker Maker
+	var err error
+
+	switch tokenType {
+	case "", TypeJWT:
+		maker, err = NewJWTMaker(key.Key)
+	case TypePaseto:
+		maker, err = NewPasetoMaker(key.Key)
+	case TypePasetoPublic, TypeJWTEdDSA:
+		maker, err = newEd25519Maker(tokenType, key.Key, key.PublicKey)
+	default:
+		return nil, fmt.Errorf("unsupported token type: %s", tokenType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch m := maker.(type) {
+	case *JWTMaker:
+		m.keyID = key.ID
+	case *PasetoMaker:
+		m.keyID = key.ID
+	case *PasetoPublicMaker:
+		m.keyID = key.ID
+	case *JWTEdDSAMaker:
+		m.keyID = key.ID
+	}
+
+	return maker, nil
+}
+
+// CreateToken creates a new token with the current key
+func (maker *KeyringMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	return maker.current.CreateToken(username, role, duration)
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *KeyringMaker) VerifyToken(token string) (*Payload, error) {
+	keyID, err := tokenKeyID(token)
+	if err != nil {
+		return nil, err
+	}
+
+	keyMaker, ok := maker.makers[keyID]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	if expiresAt := maker.expiresAt[keyID]; !expiresAt.IsZero() && time.Now().After(expiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	return keyMaker.VerifyToken(token)
+}
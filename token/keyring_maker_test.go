@@ -0,0 +1,188 @@
+package token
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomKeys(t *testing.T, tokenType string, n int) []Key {
+	keys := make([]Key, n)
+	for i := range keys {
+		keys[i].ID = util.RandomString(8)
+		switch tokenType {
+		case TypePasetoPublic, TypeJWTEdDSA:
+			keys[i].Key = hex.EncodeToString(randomEd25519Key(t).Seed())
+		default:
+			keys[i].Key = util.RandomString(32)
+		}
+	}
+	return keys
+}
+
+func TestKeyringMakerRotation(t *testing.T) {
+	for _, tokenType := range []string{TypeJWT, TypePaseto, TypePasetoPublic, TypeJWTEdDSA} {
+		t.Run(tokenType, func(t *testing.T) {
+			keys := randomKeys(t, tokenType, 2)
+			username := util.RandomOwner()
+
+			// a token made before the rotation, when key 0 was current
+			oldMaker, err := NewKeyringMaker(tokenType, keys[:1])
+			require.NoError(t, err)
+
+			oldToken, _, err := oldMaker.CreateToken(username, util.DepositorRole, time.Minute)
+			require.NoError(t, err)
+
+			keyID, err := tokenKeyID(oldToken)
+			require.NoError(t, err)
+			require.Equal(t, keys[0].ID, keyID)
+
+			// rotate: key 1 becomes current, key 0 is kept to verify old tokens
+			maker, err := NewKeyringMaker(tokenType, []Key{keys[1], keys[0]})
+			require.NoError(t, err)
+
+			newToken, _, err := maker.CreateToken(username, util.DepositorRole, time.Minute)
+			require.NoError(t, err)
+
+			keyID, err = tokenKeyID(newToken)
+			require.NoError(t, err)
+			require.Equal(t, keys[1].ID, keyID)
+
+			payload, err := maker.VerifyToken(oldToken)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+
+			payload, err = maker.VerifyToken(newToken)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+
+			// once key 0 ages out its tokens are rejected
+			keys[0].ExpiresAt = time.Now().Add(-time.Second)
+			maker, err = NewKeyringMaker(tokenType, []Key{keys[1], keys[0]})
+			require.NoError(t, err)
+
+			payload, err = maker.VerifyToken(oldToken)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+
+			// and so are the tokens of keys removed from the keyring
+			maker, err = NewKeyringMaker(tokenType, keys[1:])
+			require.NoError(t, err)
+
+			payload, err = maker.VerifyToken(oldToken)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestKeyringMakerLegacyToken(t *testing.T) {
+	keys := randomKeys(t, TypeJWT, 2)
+
+	// a token made by the single key maker before the keyring was introduced
+	legacyMaker, err := NewJWTMaker(keys[1].Key)
+	require.NoError(t, err)
+
+	token, _, err := legacyMaker.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	maker, err := NewKeyringMaker(TypeJWT, keys)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// keeping the old key without an ID lets its tokens through
+	keys[1].ID = ""
+	maker, err = NewKeyringMaker(TypeJWT, keys)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+}
+
+func TestKeyringMakerForgedKeyID(t *testing.T) {
+	keys := randomKeys(t, TypeJWT, 2)
+
+	// a token signed with an unknown key but claiming the ID of a keyring key
+	forger, err := NewKeyringMaker(TypeJWT, []Key{{ID: keys[0].ID, Key: util.RandomString(32)}})
+	require.NoError(t, err)
+
+	token, _, err := forger.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	maker, err := NewKeyringMaker(TypeJWT, keys)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestKeyringMakerExpiredToken(t *testing.T) {
+	maker, err := NewKeyringMaker(TypePaseto, randomKeys(t, TypePaseto, 1))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestNewKeyringMakerInvalidKeys(t *testing.T) {
+	_, err := NewKeyringMaker(TypeJWT, nil)
+	require.Error(t, err)
+
+	_, err = NewKeyringMaker(TypeJWT, []Key{{Key: util.RandomString(32)}})
+	require.Error(t, err)
+
+	key := Key{ID: util.RandomString(8), Key: util.RandomString(32)}
+	_, err = NewKeyringMaker(TypeJWT, []Key{key, key})
+	require.Error(t, err)
+
+	_, err = NewKeyringMaker(TypePaseto, []Key{{ID: util.RandomString(8), Key: util.RandomString(10)}})
+	require.Error(t, err)
+}
+
+func TestParseKeyList(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	keys, err := ParseKeyList("new:" + "abc" + ", old:def:" + expiresAt.Format(time.RFC3339))
+	require.NoError(t, err)
+	require.Equal(t, []Key{
+		{ID: "new", Key: "abc"},
+		{ID: "old", Key: "def", ExpiresAt: expiresAt},
+	}, keys)
+
+	_, err = ParseKeyList("no-key")
+	require.Error(t, err)
+
+	_, err = ParseKeyList("old:def:yesterday")
+	require.Error(t, err)
+}
+
+func TestLoadKeyringFile(t *testing.T) {
+	keys := randomKeys(t, TypeJWT, 2)
+	keys[1].ExpiresAt = time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	data, err := json.Marshal(keys)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	loaded, err := LoadKeyringFile(path)
+	require.NoError(t, err)
+	require.Equal(t, keys, loaded)
+
+	_, err = LoadKeyringFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+)
+
+// ParseEd25519PrivateKey decodes a hex encoded 32-byte Ed25519 seed into a private key
+func ParseEd25519PrivateKey(key string) (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid private key size: must be exactly %d bytes", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParseEd25519PublicKey decodes a hex encoded 32-byte Ed25519 public key
+func ParseEd25519PublicKey(key string) (ed25519.PublicKey, error) {
+	publicKey, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: must be exactly %d bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(publicKey), nil
+}
@@ -1,14 +1,92 @@
 package token
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/forabbie/vank-app/util"
+)
+
+// Supported values of the TOKEN_TYPE config
+const (
+	TypeJWT          = "jwt"           // JWT signed with HS256 and TOKEN_SYMMETRIC_KEY
+	TypePaseto       = "paseto"        // PASETO v2.local encrypted with TOKEN_SYMMETRIC_KEY
+	TypePasetoPublic = "paseto_public" // PASETO v2.public signed with the Ed25519 TOKEN_PRIVATE_KEY
+	TypeJWTEdDSA     = "jwt_eddsa"     // JWT signed with EdDSA and the Ed25519 TOKEN_PRIVATE_KEY
 )
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	// CreateToken creates a new token for a specific username, role and duration
+	CreateToken(username string, role string, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
 }
+
+// NewMaker creates the token maker selected by the TOKEN_TYPE config, defaulting to JWT.
+// When a keyring is configured the maker rotates between its keys instead of using a single one.
+func NewMaker(config util.Config) (Maker, error) {
+	if config.TokenKeyringFile != "" {
+		keys, err := LoadKeyringFile(config.TokenKeyringFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeyringMaker(config.TokenType, keys)
+	}
+
+	if config.TokenKeys != "" {
+		keys, err := ParseKeyList(config.TokenKeys)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeyringMaker(config.TokenType, keys)
+	}
+
+	switch config.TokenType {
+	case "", TypeJWT:
+		return NewJWTMaker(config.TokenSymmetricKey)
+	case TypePaseto:
+		return NewPasetoMaker(config.TokenSymmetricKey)
+	case TypePasetoPublic, TypeJWTEdDSA:
+		return newEd25519Maker(config.TokenType, config.TokenPrivateKey, config.TokenPublicKey)
+	default:
+		return nil, fmt.Errorf("unsupported token type: %s", config.TokenType)
+	}
+}
+
+// newEd25519Maker creates an asymmetric maker from hex encoded keys.
+// Services that only verify tokens are configured with the public key alone.
+func newEd25519Maker(tokenType string, privateKeyHex string, publicKeyHex string) (Maker, error) {
+	if privateKeyHex == "" {
+		publicKey, err := ParseEd25519PublicKey(publicKeyHex)
+		if err != nil {
+			return nil, err
+		}
+
+		if tokenType == TypePasetoPublic {
+			return NewPasetoPublicVerifier(publicKey)
+		}
+		return NewJWTEdDSAVerifier(publicKey)
+	}
+
+	privateKey, err := ParseEd25519PrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	if publicKeyHex != "" {
+		publicKey, err := ParseEd25519PublicKey(publicKeyHex)
+		if err != nil {
+			return nil, err
+		}
+		if !publicKey.Equal(privateKey.Public()) {
+			return nil, fmt.Errorf("public key doesn't match the private key")
+		}
+	}
+
+	if tokenType == TypePasetoPublic {
+		return NewPasetoPublicMaker(privateKey)
+	}
+	return NewJWTEdDSAMaker(privateKey)
+}
@@ -0,0 +1,116 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/forabbie/vank-app/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaker(t *testing.T) {
+	privateKey := randomEd25519Key(t)
+	privateKeyHex := hex.EncodeToString(privateKey.Seed())
+	publicKeyHex := hex.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+	otherPublicKeyHex := hex.EncodeToString(randomEd25519Key(t).Public().(ed25519.PublicKey))
+
+	testCases := []struct {
+		name       string
+		config     util.Config
+		checkMaker func(t *testing.T, maker Maker, err error)
+	}{
+		{
+			name:   "DefaultJWT",
+			config: util.Config{TokenSymmetricKey: util.RandomString(32)},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &JWTMaker{}, maker)
+			},
+		},
+		{
+			name:   "Paseto",
+			config: util.Config{TokenType: TypePaseto, TokenSymmetricKey: util.RandomString(32)},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &PasetoMaker{}, maker)
+			},
+		},
+		{
+			name:   "PasetoPublic",
+			config: util.Config{TokenType: TypePasetoPublic, TokenPrivateKey: privateKeyHex, TokenPublicKey: publicKeyHex},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &PasetoPublicMaker{}, maker)
+			},
+		},
+		{
+			name:   "JWTEdDSA",
+			config: util.Config{TokenType: TypeJWTEdDSA, TokenPrivateKey: privateKeyHex},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &JWTEdDSAMaker{}, maker)
+			},
+		},
+		{
+			name:   "PublicKeyOnly",
+			config: util.Config{TokenType: TypeJWTEdDSA, TokenPublicKey: publicKeyHex},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.NoError(t, err)
+
+				_, _, err = maker.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+				require.ErrorIs(t, err, ErrMissingPrivateKey)
+			},
+		},
+		{
+			name:   "MismatchedPublicKey",
+			config: util.Config{TokenType: TypePasetoPublic, TokenPrivateKey: privateKeyHex, TokenPublicKey: otherPublicKeyHex},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.Error(t, err)
+				require.Nil(t, maker)
+			},
+		},
+		{
+			name:   "InvalidPrivateKey",
+			config: util.Config{TokenType: TypeJWTEdDSA, TokenPrivateKey: "not-hex"},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.Error(t, err)
+				require.Nil(t, maker)
+			},
+		},
+		{
+			name:   "KeyList",
+			config: util.Config{TokenType: TypePaseto, TokenKeys: "new:" + util.RandomString(32) + ",old:" + util.RandomString(32)},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &KeyringMaker{}, maker)
+			},
+		},
+		{
+			name:   "MissingKeyringFile",
+			config: util.Config{TokenKeyringFile: "missing.json"},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.Error(t, err)
+				require.Nil(t, maker)
+			},
+		},
+		{
+			name:   "UnsupportedType",
+			config: util.Config{TokenType: "xyz", TokenSymmetricKey: util.RandomString(32)},
+			checkMaker: func(t *testing.T, maker Maker, err error) {
+				require.Error(t, err)
+				require.Nil(t, maker)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewMaker(tc.config)
+			tc.checkMaker(t, maker, err)
+		})
+	}
+}
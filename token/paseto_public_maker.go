@@ -0,0 +1,77 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"time"
+
+	"github.com/o1egl/paseto"
+)
+
+// PasetoPublicMaker signs PASETO v2.public tokens with an Ed25519 private key,
+// so they can be verified by anyone holding the public key
+type PasetoPublicMaker struct {
+	paseto     *paseto.V2
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+	keyID      string
+}
+
+// NewPasetoPublicMaker creates a new PASETO maker that signs and verifies tokens with an Ed25519 key pair
+func NewPasetoPublicMaker(privateKey ed25519.PrivateKey) (Maker, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+
+	maker := &PasetoPublicMaker{
+		paseto:     paseto.NewV2(),
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}
+
+	return maker, nil
+}
+
+// NewPasetoPublicVerifier creates a PASETO maker that can only verify tokens, using an Ed25519 public key
+func NewPasetoPublicVerifier(publicKey ed25519.PublicKey) (Maker, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+
+	maker := &PasetoPublicMaker{
+		paseto:    paseto.NewV2(),
+		publicKey: publicKey,
+	}
+
+	return maker, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	if maker.privateKey == nil {
+		return "", nil, ErrMissingPrivateKey
+	}
+
+	payload, err := NewPayload(username, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.paseto.Sign(maker.privateKey, payload, newFooter(maker.keyID))
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	payload := &Payload{}
+
+	err := maker.paseto.Verify(token, maker.publicKey, payload, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
@@ -8,15 +8,41 @@ import (
 )
 
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	EmailSenderName      string        `mapstructure:"EMAIL_SENDER_NAME"`
-	EmailSenderAddress   string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
-	EmailSenderPassword  string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	DBDriver              string        `mapstructure:"DB_DRIVER"`
+	DBSource              string        `mapstructure:"DB_SOURCE"`
+	ServerAddress         string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress     string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	GatewayServerAddress  string        `mapstructure:"GATEWAY_SERVER_ADDRESS"`
+	BaseURL               string        `mapstructure:"BASE_URL"`
+	TokenType             string        `mapstructure:"TOKEN_TYPE"`
+	TokenSymmetricKey     string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenPrivateKey       string        `mapstructure:"TOKEN_PRIVATE_KEY"`
+	TokenPublicKey        string        `mapstructure:"TOKEN_PUBLIC_KEY"`
+	TokenKeyringFile      string        `mapstructure:"TOKEN_KEYRING_FILE"`
+	TokenKeys             string        `mapstructure:"TOKEN_KEYS"`
+	AccessTokenDuration   time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration  time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	EmailSenderName       string        `mapstructure:"EMAIL_SENDER_NAME"`
+	EmailSenderAddress    string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	EmailSenderPassword   string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	LoginMaxFailures      int64         `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginMaxIPFailures    int64         `mapstructure:"LOGIN_MAX_IP_FAILURES"`
+	LoginBaseDelay        time.Duration `mapstructure:"LOGIN_BASE_DELAY"`
+	LoginLockoutDuration  time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	MfaEncryptionKey      string        `mapstructure:"MFA_ENCRYPTION_KEY"`
+	MfaChallengeDuration  time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	MfaTransferThreshold  int64         `mapstructure:"MFA_TRANSFER_THRESHOLD"`
+	CurrencyCacheDuration time.Duration `mapstructure:"CURRENCY_CACHE_DURATION"`
+	FxRatesFile           string        `mapstructure:"FX_RATES_FILE"`
+	FxRefreshInterval     time.Duration `mapstructure:"FX_REFRESH_INTERVAL"`
+	CursorSigningKey      string        `mapstructure:"CURSOR_SIGNING_KEY"`
+	// MfaTransferThresholdCurrency is the currency MfaTransferThreshold is in,
+	// transfers in other currencies are converted before comparing
+	MfaTransferThresholdCurrency string `mapstructure:"MFA_TRANSFER_THRESHOLD_CURRENCY"`
+	// Password reset requests are limited per email and per client IP within ResetPasswordWindow
+	ResetPasswordMaxRequests   int64         `mapstructure:"RESET_PASSWORD_MAX_REQUESTS"`
+	ResetPasswordMaxIPRequests int64         `mapstructure:"RESET_PASSWORD_MAX_IP_REQUESTS"`
+	ResetPasswordWindow        time.Duration `mapstructure:"RESET_PASSWORD_WINDOW"`
 }
 
 // LoadConfig loads configuration from environment variables
@@ -28,12 +54,48 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.BindEnv("DB_DRIVER")
 	viper.BindEnv("DB_SOURCE")
 	viper.BindEnv("SERVER_ADDRESS")
+	viper.BindEnv("GRPC_SERVER_ADDRESS")
+	viper.BindEnv("GATEWAY_SERVER_ADDRESS")
+	viper.BindEnv("BASE_URL")
+	viper.BindEnv("TOKEN_TYPE")
 	viper.BindEnv("TOKEN_SYMMETRIC_KEY")
+	viper.BindEnv("TOKEN_PRIVATE_KEY")
+	viper.BindEnv("TOKEN_PUBLIC_KEY")
+	viper.BindEnv("TOKEN_KEYRING_FILE")
+	viper.BindEnv("TOKEN_KEYS")
 	viper.BindEnv("ACCESS_TOKEN_DURATION")
 	viper.BindEnv("REFRESH_TOKEN_DURATION")
 	viper.BindEnv("EMAIL_SENDER_NAME")
 	viper.BindEnv("EMAIL_SENDER_ADDRESS")
 	viper.BindEnv("EMAIL_SENDER_PASSWORD")
+	viper.BindEnv("LOGIN_MAX_FAILURES")
+	viper.BindEnv("LOGIN_MAX_IP_FAILURES")
+	viper.BindEnv("LOGIN_BASE_DELAY")
+	viper.BindEnv("LOGIN_LOCKOUT_DURATION")
+	viper.BindEnv("MFA_ENCRYPTION_KEY")
+	viper.BindEnv("MFA_CHALLENGE_DURATION")
+	viper.BindEnv("MFA_TRANSFER_THRESHOLD")
+	viper.BindEnv("CURRENCY_CACHE_DURATION")
+	viper.BindEnv("FX_RATES_FILE")
+	viper.BindEnv("FX_REFRESH_INTERVAL")
+	viper.BindEnv("CURSOR_SIGNING_KEY")
+	viper.BindEnv("MFA_TRANSFER_THRESHOLD_CURRENCY")
+	viper.BindEnv("RESET_PASSWORD_MAX_REQUESTS")
+	viper.BindEnv("RESET_PASSWORD_MAX_IP_REQUESTS")
+	viper.BindEnv("RESET_PASSWORD_WINDOW")
+
+	// Brute-force protection is on unless explicitly turned off
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_MAX_IP_FAILURES", 50)
+	viper.SetDefault("LOGIN_BASE_DELAY", time.Second)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	viper.SetDefault("RESET_PASSWORD_MAX_REQUESTS", 3)
+	viper.SetDefault("RESET_PASSWORD_MAX_IP_REQUESTS", 20)
+	viper.SetDefault("RESET_PASSWORD_WINDOW", time.Hour)
+	viper.SetDefault("MFA_CHALLENGE_DURATION", 5*time.Minute)
+	viper.SetDefault("MFA_TRANSFER_THRESHOLD_CURRENCY", "USD")
+	viper.SetDefault("CURRENCY_CACHE_DURATION", time.Minute)
+	viper.SetDefault("FX_REFRESH_INTERVAL", 10*time.Minute)
 
 	viper.AutomaticEnv()
 
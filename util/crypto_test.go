@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt(t *testing.T) {
+	key := RandomString(32)
+	plaintext := RandomString(16)
+
+	ciphertext1, err := Encrypt(key, plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, plaintext, ciphertext1)
+
+	got, err := Decrypt(key, ciphertext1)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, got)
+
+	// every encryption uses a fresh nonce
+	ciphertext2, err := Encrypt(key, plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext1, ciphertext2)
+
+	_, err = Decrypt(RandomString(32), ciphertext1)
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = Decrypt(key, "invalid")
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = Encrypt(RandomString(16), plaintext)
+	require.Error(t, err)
+}
@@ -1,15 +1,8 @@
 package util
 
+// Currencies seeded by the migrations, more can be enabled in the currencies table
 const (
 	USD = "USD"
 	EUR = "EUR"
 	CAD = "CAD"
 )
-
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD:
-		return true
-	}
-	return false
-}
@@ -19,3 +19,7 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(password string, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
+
+// DummyHashedPassword is checked against when a user does not exist,
+// so that a login takes as long for unknown usernames as for wrong passwords
+const DummyHashedPassword = "$2a$10$ANQWNMwr8bw0KNaJ25A17Omd.ECzuygpv3ybV2jNFENu/52onWjCy"
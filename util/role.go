@@ -0,0 +1,21 @@
+package util
+
+// Roles a user can have
+const (
+	DepositorRole = "depositor"
+	BankerRole    = "banker"
+	AdminRole     = "admin"
+)
+
+func IsSupportedRole(role string) bool {
+	switch role {
+	case DepositorRole, BankerRole, AdminRole:
+		return true
+	}
+	return false
+}
+
+// CanViewAnyAccount reports whether the role may view accounts and transfers of other users
+func CanViewAnyAccount(role string) bool {
+	return role == BankerRole || role == AdminRole
+}
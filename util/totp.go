@@ -0,0 +1,73 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpIssuer = "Simple Bank"
+	totpPeriod = 30
+	// totpSkew is how many steps before and after the current one are accepted, to allow for clock drift
+	totpSkew = 1
+
+	recoveryCodeSize = 5
+)
+
+// NewTOTPKey generates an RFC 6238 secret for the account.
+// It returns the base32 secret and the otpauth:// URL authenticator apps enroll from.
+func NewTOTPKey(accountName string) (secret string, url string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate totp key: %w", err)
+	}
+	return key.Secret(), key.URL(), nil
+}
+
+// ValidateTOTP checks a 6 digit code against the secret at time t.
+// It returns the time step the code belongs to, so callers can refuse a code
+// from a step that was already used.
+func ValidateTOTP(secret string, code string, t time.Time) (step int64, ok bool) {
+	current := t.Unix() / totpPeriod
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := current + offset
+		want, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err == nil && want == code {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// NewRecoveryCodes generates n single-use recovery codes
+func NewRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		code, err := RandomSecret(recoveryCodeSize)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash recovery codes are stored and looked up by.
+// The codes are random, so a fast hash is enough and lets them be found without trying each one.
+func HashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
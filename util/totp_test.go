@@ -0,0 +1,50 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	secret, url, err := NewTOTPKey("alice")
+	require.NoError(t, err)
+	require.Contains(t, url, "otpauth://totp/")
+	require.Contains(t, url, secret)
+
+	now := time.Now()
+	code, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/totpPeriod, step)
+
+	// a code from the previous step is still accepted to allow for clock drift
+	_, ok = ValidateTOTP(secret, code, now.Add(totpPeriod*time.Second))
+	require.True(t, ok)
+
+	_, ok = ValidateTOTP(secret, code, now.Add(5*totpPeriod*time.Second))
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "000000x", now)
+	require.False(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, 2*recoveryCodeSize)
+		require.False(t, seen[code])
+		seen[code] = true
+
+		require.Equal(t, HashRecoveryCode(code), HashRecoveryCode(code))
+		require.NotEqual(t, code, HashRecoveryCode(code))
+	}
+}
@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"errors"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func ValidateCreateCurrencyRequest(req *db.CreateCurrencyRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateCurrencyCode(req.Code); err != nil {
+		violations = append(violations, util.CreateFieldViolation("code", err))
+	}
+
+	if err := ValidateCurrencyExponent(req.Exponent); err != nil {
+		violations = append(violations, util.CreateFieldViolation("exponent", err))
+	}
+
+	return violations
+}
+
+func ValidateUpdateCurrencyRequest(req *db.UpdateCurrencyRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateCurrencyCode(req.Code); err != nil {
+		violations = append(violations, util.CreateFieldViolation("code", err))
+	}
+
+	if req.IsEnabled == nil {
+		violations = append(violations, util.CreateFieldViolation("is_enabled", errors.New("is required")))
+	}
+
+	return violations
+}
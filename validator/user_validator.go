@@ -20,6 +20,30 @@ func ValidateLoginUserRequest(req *db.LoginUserRequest) []*errdetails.BadRequest
 	return violations
 }
 
+func ValidateVerifyLoginMfaRequest(req *db.VerifyLoginMfaRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateSessionID(req.MfaToken); err != nil {
+		violations = append(violations, util.CreateFieldViolation("mfa_token", err))
+	}
+
+	if err := ValidateMfaCode(req.Code); err != nil {
+		violations = append(violations, util.CreateFieldViolation("code", err))
+	}
+
+	return violations
+}
+
+func ValidateConfirmTotpRequest(req *db.ConfirmTotpRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateTOTPCode(req.Code); err != nil {
+		violations = append(violations, util.CreateFieldViolation("code", err))
+	}
+
+	return violations
+}
+
 func ValidateCreateUserRequest(req *db.CreateUserRequest) []*errdetails.BadRequest_FieldViolation {
 	var violations []*errdetails.BadRequest_FieldViolation
 
@@ -41,3 +65,107 @@ func ValidateCreateUserRequest(req *db.CreateUserRequest) []*errdetails.BadReque
 
 	return violations
 }
+
+func ValidateVerifyEmailRequest(req *db.VerifyEmailRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateEmailId(req.EmailId); err != nil {
+		violations = append(violations, util.CreateFieldViolation("email_id", err))
+	}
+
+	if err := ValidateSecretCode(req.SecretCode); err != nil {
+		violations = append(violations, util.CreateFieldViolation("secret_code", err))
+	}
+
+	return violations
+}
+
+func ValidateForgotPasswordRequest(req *db.ForgotPasswordRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateEmail(req.Email); err != nil {
+		violations = append(violations, util.CreateFieldViolation("email", err))
+	}
+
+	return violations
+}
+
+func ValidateResetPasswordRequest(req *db.ResetPasswordRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateID(req.ResetId); err != nil {
+		violations = append(violations, util.CreateFieldViolation("reset_id", err))
+	}
+
+	if err := ValidateSecretCode(req.SecretCode); err != nil {
+		violations = append(violations, util.CreateFieldViolation("secret_code", err))
+	}
+
+	if err := ValidatePassword(req.Password); err != nil {
+		violations = append(violations, util.CreateFieldViolation("password", err))
+	}
+
+	return violations
+}
+
+func ValidateUnlockAccountRequest(req *db.UnlockAccountRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateID(req.UnlockId); err != nil {
+		violations = append(violations, util.CreateFieldViolation("unlock_id", err))
+	}
+
+	if err := ValidateSecretCode(req.SecretCode); err != nil {
+		violations = append(violations, util.CreateFieldViolation("secret_code", err))
+	}
+
+	return violations
+}
+
+func ValidateUpdateUserRequest(req *db.UpdateUserRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateID(req.ID); err != nil {
+		violations = append(violations, util.CreateFieldViolation("id", err))
+	}
+
+	if req.Password != nil {
+		if err := ValidatePassword(*req.Password); err != nil {
+			violations = append(violations, util.CreateFieldViolation("password", err))
+		}
+	}
+
+	if req.FullName != nil {
+		if err := ValidateFullName(*req.FullName); err != nil {
+			violations = append(violations, util.CreateFieldViolation("full_name", err))
+		}
+	}
+
+	if req.Email != nil {
+		if err := ValidateEmail(*req.Email); err != nil {
+			violations = append(violations, util.CreateFieldViolation("email", err))
+		}
+	}
+
+	if req.KeepSessionID != nil {
+		if err := ValidateSessionID(*req.KeepSessionID); err != nil {
+			violations = append(violations, util.CreateFieldViolation("keep_session_id", err))
+		}
+	}
+
+	return violations
+}
+
+func ValidateUpdateUserRoleRequest(req *db.UpdateUserRoleRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if err := ValidateID(req.ID); err != nil {
+		violations = append(violations, util.CreateFieldViolation("id", err))
+	}
+
+	if err := ValidateRole(req.Role); err != nil {
+		violations = append(violations, util.CreateFieldViolation("role", err))
+	}
+
+	return violations
+}
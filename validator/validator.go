@@ -1,14 +1,22 @@
 package validator
 
 import (
+	"context"
 	"fmt"
 	"net/mail"
 	"regexp"
+
+	"github.com/forabbie/vank-app/currency"
+	"github.com/forabbie/vank-app/util"
+	"github.com/google/uuid"
 )
 
 var (
-	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
-	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidUsername     = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
+	isValidFullName     = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidTOTPCode     = regexp.MustCompile(`^[0-9]{6}$`).MatchString
+	isValidRecoveryCode = regexp.MustCompile(`^[0-9a-f]{10}$`).MatchString
+	isValidCurrencyCode = regexp.MustCompile(`^[A-Z]{3}$`).MatchString
 )
 
 func ValidateString(value string, minLength, maxLength int) error {
@@ -60,6 +68,78 @@ func ValidateEmailId(value int64) error {
 	return nil
 }
 
+func ValidateID(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
 func ValidateSecretCode(value string) error {
 	return ValidateString(value, 32, 128)
 }
+
+func ValidateSessionID(value string) error {
+	if _, err := uuid.Parse(value); err != nil {
+		return fmt.Errorf("must be a valid UUID")
+	}
+	return nil
+}
+
+func ValidateTOTPCode(value string) error {
+	if !isValidTOTPCode(value) {
+		return fmt.Errorf("must be a 6 digit code")
+	}
+	return nil
+}
+
+// ValidateMfaCode accepts either a TOTP code or a recovery code
+func ValidateMfaCode(value string) error {
+	if !isValidTOTPCode(value) && !isValidRecoveryCode(value) {
+		return fmt.Errorf("must be a 6 digit code or a recovery code")
+	}
+	return nil
+}
+
+// ValidateCurrency checks that the currency is enabled in the registry
+func ValidateCurrency(ctx context.Context, currencies *currency.Registry, value string) error {
+	if !currencies.IsSupported(ctx, value) {
+		return fmt.Errorf("is not a supported currency")
+	}
+	return nil
+}
+
+func ValidateCurrencyCode(value string) error {
+	if !isValidCurrencyCode(value) {
+		return fmt.Errorf("must be a 3 letter ISO 4217 code")
+	}
+	return nil
+}
+
+func ValidateCurrencyExponent(value int32) error {
+	if value < 0 || value > 4 {
+		return fmt.Errorf("must be from 0-4")
+	}
+	return nil
+}
+
+func ValidateRole(value string) error {
+	if !util.IsSupportedRole(value) {
+		return fmt.Errorf("is not a supported role")
+	}
+	return nil
+}
+
+func ValidatePage(value int32) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateLimit(value int32, minLimit int32, maxLimit int32) error {
+	if value < minLimit || value > maxLimit {
+		return fmt.Errorf("must be from %d-%d", minLimit, maxLimit)
+	}
+	return nil
+}
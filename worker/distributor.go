@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+)
+
+const defaultMaxAttempts = 10
+
+// TaskDistributor enqueues background tasks into the tasks table.
+// Every method takes the queries of the caller's transaction, so a task is only
+// visible to the processor once the work that produced it has been committed.
+type TaskDistributor interface {
+	DistributeTaskSendVerifyEmail(
+		ctx context.Context,
+		q db.Querier,
+		payload *PayloadSendVerifyEmail,
+		opts ...Option,
+	) error
+	DistributeTaskSendUnlockAccountEmail(
+		ctx context.Context,
+		q db.Querier,
+		payload *PayloadSendUnlockAccountEmail,
+		opts ...Option,
+	) error
+	DistributeTaskSendResetPasswordEmail(
+		ctx context.Context,
+		q db.Querier,
+		payload *PayloadSendResetPasswordEmail,
+		opts ...Option,
+	) error
+	DistributeTaskSendStatementEmail(
+		ctx context.Context,
+		q db.Querier,
+		payload *PayloadSendStatementEmail,
+		opts ...Option,
+	) error
+}
+
+// Option customizes how a task is enqueued
+type Option func(*db.CreateTaskParams)
+
+// MaxAttempts sets how many times a task is tried before it is dead-lettered
+func MaxAttempts(n int32) Option {
+	return func(arg *db.CreateTaskParams) {
+		arg.MaxAttempts = n
+	}
+}
+
+// ProcessIn delays the first attempt of a task
+func ProcessIn(d time.Duration) Option {
+	return func(arg *db.CreateTaskParams) {
+		arg.RunAt = arg.RunAt.Add(d)
+	}
+}
+
+type PostgresTaskDistributor struct{}
+
+// NewPostgresTaskDistributor creates a new task distributor backed by the tasks table
+func NewPostgresTaskDistributor() TaskDistributor {
+	return &PostgresTaskDistributor{}
+}
+
+func (distributor *PostgresTaskDistributor) enqueue(
+	ctx context.Context,
+	q db.Querier,
+	taskType string,
+	payload interface{},
+	opts ...Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	arg := db.CreateTaskParams{
+		Type:        taskType,
+		Payload:     jsonPayload,
+		MaxAttempts: defaultMaxAttempts,
+		RunAt:       time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+
+	_, err = q.CreateTask(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task %s: %w", taskType, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistributeTaskSendVerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	distributor := NewPostgresTaskDistributor()
+	payload := &PayloadSendVerifyEmail{Username: util.RandomOwner()}
+
+	store.EXPECT().
+		CreateTask(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateTaskParams) (db.Task, error) {
+			require.Equal(t, TaskSendVerifyEmail, arg.Type)
+			require.Equal(t, int32(3), arg.MaxAttempts)
+			require.WithinDuration(t, time.Now().Add(time.Minute), arg.RunAt, time.Second)
+
+			var got PayloadSendVerifyEmail
+			require.NoError(t, json.Unmarshal(arg.Payload, &got))
+			require.Equal(t, *payload, got)
+			return db.Task{}, nil
+		})
+
+	err := distributor.DistributeTaskSendVerifyEmail(context.Background(), store, payload, MaxAttempts(3), ProcessIn(time.Minute))
+	require.NoError(t, err)
+}
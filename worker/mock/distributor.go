@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/forabbie/vank-app/worker (interfaces: TaskDistributor)
+
+// Package mock_worker is a generated GoMock package.
+package mock_worker
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	worker "github.com/forabbie/vank-app/worker"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTaskDistributor is a mock of TaskDistributor interface.
+type MockTaskDistributor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskDistributorMockRecorder
+}
+
+// MockTaskDistributorMockRecorder is the mock recorder for MockTaskDistributor.
+type MockTaskDistributorMockRecorder struct {
+	mock *MockTaskDistributor
+}
+
+// NewMockTaskDistributor creates a new mock instance.
+func NewMockTaskDistributor(ctrl *gomock.Controller) *MockTaskDistributor {
+	mock := &MockTaskDistributor{ctrl: ctrl}
+	mock.recorder = &MockTaskDistributorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskDistributor) EXPECT() *MockTaskDistributorMockRecorder {
+	return m.recorder
+}
+
+// DistributeTaskSendResetPasswordEmail mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendResetPasswordEmail(arg0 context.Context, arg1 db.Querier, arg2 *worker.PayloadSendResetPasswordEmail, arg3 ...worker.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendResetPasswordEmail", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendResetPasswordEmail indicates an expected call of DistributeTaskSendResetPasswordEmail.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendResetPasswordEmail(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendResetPasswordEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendResetPasswordEmail), varargs...)
+}
+
+// DistributeTaskSendStatementEmail mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendStatementEmail(arg0 context.Context, arg1 db.Querier, arg2 *worker.PayloadSendStatementEmail, arg3 ...worker.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendStatementEmail", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendStatementEmail indicates an expected call of DistributeTaskSendStatementEmail.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendStatementEmail(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendStatementEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendStatementEmail), varargs...)
+}
+
+// DistributeTaskSendUnlockAccountEmail mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendUnlockAccountEmail(arg0 context.Context, arg1 db.Querier, arg2 *worker.PayloadSendUnlockAccountEmail, arg3 ...worker.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendUnlockAccountEmail", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendUnlockAccountEmail indicates an expected call of DistributeTaskSendUnlockAccountEmail.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendUnlockAccountEmail(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendUnlockAccountEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendUnlockAccountEmail), varargs...)
+}
+
+// DistributeTaskSendVerifyEmail mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendVerifyEmail(arg0 context.Context, arg1 db.Querier, arg2 *worker.PayloadSendVerifyEmail, arg3 ...worker.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendVerifyEmail", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendVerifyEmail indicates an expected call of DistributeTaskSendVerifyEmail.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendVerifyEmail(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendVerifyEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendVerifyEmail), varargs...)
+}
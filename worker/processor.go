@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/forabbie/vank-app/currency"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/mail"
+	"github.com/forabbie/vank-app/util"
+)
+
+const (
+	pollInterval = time.Second
+	batchSize    = 10
+	// leaseDuration is how long a claimed task stays hidden from other processors.
+	// A task whose processor dies mid-way is picked up again once the lease expires.
+	leaseDuration = 5 * time.Minute
+	minBackoff    = 10 * time.Second
+	maxBackoff    = time.Hour
+)
+
+// ErrSkipRetry makes the processor dead-letter a task straight away instead of retrying it
+var ErrSkipRetry = errors.New("skip retry for the task")
+
+// TaskProcessor runs the background tasks enqueued by a TaskDistributor
+type TaskProcessor interface {
+	Start() error
+	Shutdown()
+}
+
+type taskHandler func(ctx context.Context, payload []byte) error
+
+type PostgresTaskProcessor struct {
+	config     util.Config
+	store      db.Store
+	mailer     mail.EmailSender
+	currencies *currency.Registry
+	handlers   map[string]taskHandler
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewPostgresTaskProcessor creates a new task processor polling the tasks table
+func NewPostgresTaskProcessor(config util.Config, store db.Store, mailer mail.EmailSender) TaskProcessor {
+	processor := &PostgresTaskProcessor{
+		config:     config,
+		store:      store,
+		mailer:     mailer,
+		currencies: currency.NewRegistry(store, config.CurrencyCacheDuration),
+	}
+
+	processor.handlers = map[string]taskHandler{
+		TaskSendVerifyEmail:        processor.ProcessTaskSendVerifyEmail,
+		TaskSendUnlockAccountEmail: processor.ProcessTaskSendUnlockAccountEmail,
+		TaskSendResetPasswordEmail: processor.ProcessTaskSendResetPasswordEmail,
+		TaskSendStatementEmail:     processor.ProcessTaskSendStatementEmail,
+	}
+
+	return processor
+}
+
+// Start starts polling for tasks in the background
+func (processor *PostgresTaskProcessor) Start() error {
+	// Statements are formatted with the exponents of the currencies, so they must be known first
+	if err := processor.currencies.Load(context.Background()); err != nil {
+		return fmt.Errorf("cannot load currencies: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	processor.cancel = cancel
+
+	processor.wg.Add(1)
+	go func() {
+		defer processor.wg.Done()
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				processor.processBatch(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops polling and waits for the tasks in progress to finish
+func (processor *PostgresTaskProcessor) Shutdown() {
+	if processor.cancel != nil {
+		processor.cancel()
+	}
+	processor.wg.Wait()
+}
+
+func (processor *PostgresTaskProcessor) processBatch(ctx context.Context) {
+	tasks, err := processor.store.ClaimTasks(ctx, db.ClaimTasksParams{
+		LeaseExpiresAt: time.Now().Add(leaseDuration),
+		BatchSize:      batchSize,
+	})
+	if err != nil {
+		log.Printf("cannot claim tasks: %v", err)
+		return
+	}
+
+	for _, task := range tasks {
+		processor.processTask(ctx, task)
+	}
+}
+
+// processTask runs the handler of a claimed task and records the outcome:
+// completed on success, rescheduled with backoff on failure, or dead-lettered
+// once it has used up its attempts.
+func (processor *PostgresTaskProcessor) processTask(ctx context.Context, task db.Task) {
+	var err error
+
+	handler, ok := processor.handlers[task.Type]
+	if ok {
+		err = handler(ctx, task.Payload)
+	} else {
+		err = fmt.Errorf("unknown task type %s: %w", task.Type, ErrSkipRetry)
+	}
+
+	if err == nil {
+		if err := processor.store.CompleteTask(ctx, task.ID); err != nil {
+			log.Printf("cannot complete task %d: %v", task.ID, err)
+		}
+		return
+	}
+
+	lastError := sql.NullString{String: err.Error(), Valid: true}
+
+	if errors.Is(err, ErrSkipRetry) || task.Attempts >= task.MaxAttempts {
+		log.Printf("task %d (%s) failed permanently after %d attempts: %v", task.ID, task.Type, task.Attempts, err)
+		err = processor.store.DeadLetterTask(ctx, db.DeadLetterTaskParams{
+			ID:        task.ID,
+			LastError: lastError,
+		})
+		if err != nil {
+			log.Printf("cannot dead-letter task %d: %v", task.ID, err)
+		}
+		return
+	}
+
+	log.Printf("task %d (%s) failed, attempt %d of %d: %v", task.ID, task.Type, task.Attempts, task.MaxAttempts, err)
+	err = processor.store.RetryTask(ctx, db.RetryTaskParams{
+		ID:        task.ID,
+		RunAt:     time.Now().Add(backoff(task.Attempts)),
+		LastError: lastError,
+	})
+	if err != nil {
+		log.Printf("cannot reschedule task %d: %v", task.ID, err)
+	}
+}
+
+// backoff returns how long to wait before the next attempt,
+// doubling from minBackoff after every failed attempt up to maxBackoff
+func backoff(attempts int32) time.Duration {
+	d := minBackoff
+	for i := int32(1); i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,361 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockdb "github.com/forabbie/vank-app/database/mock"
+	db "github.com/forabbie/vank-app/database/sqlc"
+	mockmail "github.com/forabbie/vank-app/mail/mock"
+	"github.com/forabbie/vank-app/statement"
+	"github.com/forabbie/vank-app/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProcessor(store db.Store, mailer *mockmail.MockEmailSender) *PostgresTaskProcessor {
+	config := util.Config{BaseURL: "http://localhost:8080"}
+	return NewPostgresTaskProcessor(config, store, mailer).(*PostgresTaskProcessor)
+}
+
+func TestProcessTask(t *testing.T) {
+	errTemporary := errors.New("temporary failure")
+
+	testCases := []struct {
+		name       string
+		task       db.Task
+		handlerErr error
+		buildStubs func(store *mockdb.MockStore, task db.Task)
+	}{
+		{
+			name: "Completed",
+			task: db.Task{ID: 1, Type: "task:test", Attempts: 1, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore, task db.Task) {
+				store.EXPECT().CompleteTask(gomock.Any(), gomock.Eq(task.ID)).Times(1)
+			},
+		},
+		{
+			name:       "Retried",
+			task:       db.Task{ID: 2, Type: "task:test", Attempts: 2, MaxAttempts: 3},
+			handlerErr: errTemporary,
+			buildStubs: func(store *mockdb.MockStore, task db.Task) {
+				store.EXPECT().
+					RetryTask(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RetryTaskParams) error {
+						require.Equal(t, task.ID, arg.ID)
+						require.Equal(t, errTemporary.Error(), arg.LastError.String)
+						require.WithinDuration(t, time.Now().Add(backoff(task.Attempts)), arg.RunAt, time.Second)
+						return nil
+					})
+				store.EXPECT().DeadLetterTask(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:       "OutOfAttempts",
+			task:       db.Task{ID: 3, Type: "task:test", Attempts: 3, MaxAttempts: 3},
+			handlerErr: errTemporary,
+			buildStubs: func(store *mockdb.MockStore, task db.Task) {
+				arg := db.DeadLetterTaskParams{
+					ID:        task.ID,
+					LastError: sql.NullString{String: errTemporary.Error(), Valid: true},
+				}
+				store.EXPECT().DeadLetterTask(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().RetryTask(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:       "SkipRetry",
+			task:       db.Task{ID: 4, Type: "task:test", Attempts: 1, MaxAttempts: 3},
+			handlerErr: ErrSkipRetry,
+			buildStubs: func(store *mockdb.MockStore, task db.Task) {
+				store.EXPECT().DeadLetterTask(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().RetryTask(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "UnknownType",
+			task: db.Task{ID: 5, Type: "task:unknown", Attempts: 1, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore, task db.Task) {
+				store.EXPECT().DeadLetterTask(gomock.Any(), gomock.Any()).Times(1)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.task)
+
+			processor := newTestProcessor(store, mockmail.NewMockEmailSender(ctrl))
+			processor.handlers["task:test"] = func(ctx context.Context, payload []byte) error {
+				return tc.handlerErr
+			}
+
+			processor.processTask(context.Background(), tc.task)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, minBackoff, backoff(1))
+	require.Equal(t, 2*minBackoff, backoff(2))
+	require.Equal(t, 4*minBackoff, backoff(3))
+	require.Equal(t, maxBackoff, backoff(100))
+}
+
+func TestProcessTaskSendVerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	processor := newTestProcessor(store, mailer)
+
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+	}
+
+	store.EXPECT().
+		GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		CreateVerifyEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateVerifyEmailParams) (db.VerifyEmail, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.Equal(t, user.Email, arg.Email)
+			require.Len(t, arg.SecretCode, 2*verifyEmailSecretSize)
+			return db.VerifyEmail{ID: 1, Username: arg.Username, Email: arg.Email, SecretCode: arg.SecretCode}, nil
+		})
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	payload, err := json.Marshal(PayloadSendVerifyEmail{Username: user.Username})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendVerifyEmail(context.Background(), payload)
+	require.NoError(t, err)
+}
+
+func TestProcessTaskSendVerifyEmailUserNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	processor := newTestProcessor(store, mailer)
+
+	store.EXPECT().
+		GetUserByUsername(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	payload, err := json.Marshal(PayloadSendVerifyEmail{Username: util.RandomOwner()})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendVerifyEmail(context.Background(), payload)
+	require.ErrorIs(t, err, ErrSkipRetry)
+}
+
+func TestProcessTaskSendUnlockAccountEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	processor := newTestProcessor(store, mailer)
+
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+	}
+
+	store.EXPECT().
+		GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		CreateUnlockAccount(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateUnlockAccountParams) (db.UnlockAccount, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.Len(t, arg.SecretCode, 2*unlockAccountSecretSize)
+			return db.UnlockAccount{ID: 1, Username: arg.Username, SecretCode: arg.SecretCode}, nil
+		})
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(subject string, content string, to, cc, bcc, attachFiles []string) error {
+			require.Contains(t, content, "http://localhost:8080/api/v1/users/unlock?unlock_id=1&secret_code=")
+			return nil
+		})
+
+	payload, err := json.Marshal(PayloadSendUnlockAccountEmail{Username: user.Username})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendUnlockAccountEmail(context.Background(), payload)
+	require.NoError(t, err)
+}
+
+func TestProcessTaskSendResetPasswordEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	processor := newTestProcessor(store, mailer)
+
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+	}
+
+	var secretCode string
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		CreateResetPassword(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateResetPasswordParams) (db.ResetPassword, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.Equal(t, user.Email, arg.Email)
+			require.Len(t, arg.SecretCode, 2*resetPasswordSecretSize)
+			secretCode = arg.SecretCode
+			return db.ResetPassword{ID: 1, Username: arg.Username, Email: arg.Email, SecretCode: arg.SecretCode}, nil
+		})
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(subject string, content string, to, cc, bcc, attachFiles []string) error {
+			require.Contains(t, content, secretCode)
+			require.Contains(t, content, "http://localhost:8080/api/v1/users/password/reset")
+			return nil
+		})
+
+	payload, err := json.Marshal(PayloadSendResetPasswordEmail{Email: user.Email})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendResetPasswordEmail(context.Background(), payload)
+	require.NoError(t, err)
+}
+
+func TestProcessTaskSendResetPasswordEmailUnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	processor := newTestProcessor(store, mailer)
+
+	email := util.RandomEmail()
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().
+		CreateResetPassword(gomock.Any(), gomock.Any()).
+		Times(0)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	payload, err := json.Marshal(PayloadSendResetPasswordEmail{Email: email})
+	require.NoError(t, err)
+
+	// Nothing is sent, and the task completes instead of being retried
+	err = processor.ProcessTaskSendResetPasswordEmail(context.Background(), payload)
+	require.NoError(t, err)
+}
+
+func TestProcessTaskSendStatementEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	processor := newTestProcessor(store, mailer)
+
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+	}
+	account := db.Account{ID: util.RandomInt(1, 1000), Owner: user.Username, Currency: "JPY"}
+	period := statement.Period{
+		From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	store.EXPECT().
+		GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	arg := db.StatementTxParams{
+		AccountID: account.ID,
+		From:      period.From,
+		To:        period.End(),
+	}
+	store.EXPECT().
+		StatementTx(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(db.StatementTxResult{Account: account, OpeningBalance: 1500, ClosingBalance: 1500}, nil)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return([]db.Currency{{Code: "JPY", Exponent: 0, IsEnabled: true}}, nil)
+
+	var attachment string
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(subject string, content string, to, cc, bcc, attachFiles []string) error {
+			require.Len(t, attachFiles, 1)
+			attachment = attachFiles[0]
+			require.Equal(t, fmt.Sprintf("statement-%d-2026-01-01-2026-01-31.csv", account.ID), filepath.Base(attachment))
+
+			data, err := os.ReadFile(attachment)
+			require.NoError(t, err)
+			require.Contains(t, string(data), "Opening balance,,1500,JPY")
+			return nil
+		})
+
+	payload, err := json.Marshal(PayloadSendStatementEmail{
+		Username:  user.Username,
+		AccountID: account.ID,
+		Period:    period,
+		Format:    statement.FormatCSV,
+	})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendStatementEmail(context.Background(), payload)
+	require.NoError(t, err)
+
+	// the file only lives as long as the task
+	_, err = os.Stat(attachment)
+	require.True(t, os.IsNotExist(err))
+}
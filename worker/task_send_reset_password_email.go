@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+)
+
+const (
+	TaskSendResetPasswordEmail = "task:send_reset_password_email"
+	resetPasswordSecretSize    = 32
+)
+
+type PayloadSendResetPasswordEmail struct {
+	// Email is as requested, it may not belong to any user
+	Email string `json:"email,omitempty"`
+	// Username is set by tasks enqueued before requests were made by email
+	Username string `json:"username,omitempty"`
+}
+
+func (distributor *PostgresTaskDistributor) DistributeTaskSendResetPasswordEmail(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadSendResetPasswordEmail,
+	opts ...Option,
+) error {
+	return distributor.enqueue(ctx, q, TaskSendResetPasswordEmail, payload, opts...)
+}
+
+// ProcessTaskSendResetPasswordEmail creates a reset password record for the user and emails them its code
+func (processor *PostgresTaskProcessor) ProcessTaskSendResetPasswordEmail(ctx context.Context, payload []byte) error {
+	var p PayloadSendResetPasswordEmail
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", ErrSkipRetry)
+	}
+
+	var user db.User
+	var err error
+	if p.Email != "" {
+		user, err = processor.store.GetUserByEmail(ctx, p.Email)
+		if err == sql.ErrNoRows {
+			// Anyone can request a reset for any email, there is nothing to send when it isn't registered
+			return nil
+		}
+	} else {
+		user, err = processor.store.GetUserByUsername(ctx, p.Username)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user %s doesn't exist: %w", p.Username, ErrSkipRetry)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secretCode, err := util.RandomSecret(resetPasswordSecretSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate secret code: %w", err)
+	}
+
+	resetPassword, err := processor.store.CreateResetPassword(ctx, db.CreateResetPasswordParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: secretCode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reset password: %w", err)
+	}
+
+	resetURL := fmt.Sprintf("%s/api/v1/users/password/reset", processor.config.BaseURL)
+	subject := "Reset your Simple Bank password"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	We received a request to reset the password of your account.<br/>
+	To choose a new one, send reset ID <b>%d</b> and code <b>%s</b> to %s within 15 minutes.<br/>
+	If this wasn't you, ignore this email and your password stays the same.<br/>
+	`, user.FullName, resetPassword.ID, resetPassword.SecretCode, resetURL)
+	to := []string{resetPassword.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send reset password email: %w", err)
+	}
+
+	return nil
+}
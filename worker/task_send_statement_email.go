@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/statement"
+)
+
+const TaskSendStatementEmail = "task:send_statement_email"
+
+type PayloadSendStatementEmail struct {
+	Username  string           `json:"username"`
+	AccountID int64            `json:"account_id"`
+	Period    statement.Period `json:"period"`
+	Format    string           `json:"format"`
+}
+
+func (distributor *PostgresTaskDistributor) DistributeTaskSendStatementEmail(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadSendStatementEmail,
+	opts ...Option,
+) error {
+	return distributor.enqueue(ctx, q, TaskSendStatementEmail, payload, opts...)
+}
+
+// ProcessTaskSendStatementEmail renders the statement of an account and emails it to the user as an attachment
+func (processor *PostgresTaskProcessor) ProcessTaskSendStatementEmail(ctx context.Context, payload []byte) error {
+	var p PayloadSendStatementEmail
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", ErrSkipRetry)
+	}
+
+	user, err := processor.store.GetUserByUsername(ctx, p.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user %s doesn't exist: %w", p.Username, ErrSkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	result, err := processor.store.StatementTx(ctx, db.StatementTxParams{
+		AccountID: p.AccountID,
+		From:      p.Period.From,
+		To:        p.Period.End(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("account %d doesn't exist: %w", p.AccountID, ErrSkipRetry)
+		}
+		return fmt.Errorf("failed to get statement: %w", err)
+	}
+
+	// The mailer attaches files from disk
+	dir, err := os.MkdirTemp("", "statement")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file, err := statement.New(ctx, processor.currencies, result, p.Period)
+	if err != nil {
+		return fmt.Errorf("failed to build statement: %w", err)
+	}
+	path := filepath.Join(dir, file.Filename(p.Format))
+	if err := writeStatement(path, file, p.Format); err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Your Simple Bank statement for account #%d", p.AccountID)
+	content := fmt.Sprintf(`Hello %s,<br/>
+	Please find attached the statement of account #%d from %s to %s.<br/>
+	`, user.FullName, p.AccountID, p.Period.From.Format(statement.DateFormat), p.Period.To.Format(statement.DateFormat))
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, []string{path})
+	if err != nil {
+		return fmt.Errorf("failed to send statement email: %w", err)
+	}
+
+	return nil
+}
+
+func writeStatement(path string, file statement.Statement, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create statement file: %w", err)
+	}
+	defer f.Close()
+
+	if err := file.Render(f, format); err != nil {
+		return fmt.Errorf("failed to render statement: %w", err)
+	}
+	return f.Close()
+}
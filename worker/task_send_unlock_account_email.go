@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+)
+
+const (
+	TaskSendUnlockAccountEmail = "task:send_unlock_account_email"
+	unlockAccountSecretSize    = 32
+)
+
+type PayloadSendUnlockAccountEmail struct {
+	Username string `json:"username"`
+}
+
+func (distributor *PostgresTaskDistributor) DistributeTaskSendUnlockAccountEmail(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadSendUnlockAccountEmail,
+	opts ...Option,
+) error {
+	return distributor.enqueue(ctx, q, TaskSendUnlockAccountEmail, payload, opts...)
+}
+
+// ProcessTaskSendUnlockAccountEmail creates an unlock record for a locked out user and emails them the unlock link
+func (processor *PostgresTaskProcessor) ProcessTaskSendUnlockAccountEmail(ctx context.Context, payload []byte) error {
+	var p PayloadSendUnlockAccountEmail
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", ErrSkipRetry)
+	}
+
+	user, err := processor.store.GetUserByUsername(ctx, p.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user %s doesn't exist: %w", p.Username, ErrSkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secretCode, err := util.RandomSecret(unlockAccountSecretSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate secret code: %w", err)
+	}
+
+	unlockAccount, err := processor.store.CreateUnlockAccount(ctx, db.CreateUnlockAccountParams{
+		Username:   user.Username,
+		SecretCode: secretCode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create unlock account: %w", err)
+	}
+
+	unlockURL := fmt.Sprintf("%s/api/v1/users/unlock?unlock_id=%d&secret_code=%s",
+		processor.config.BaseURL, unlockAccount.ID, unlockAccount.SecretCode)
+	subject := "Your Simple Bank account was locked"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	We locked your account after several failed login attempts.<br/>
+	If this was you, <a href="%s">click here</a> to unlock it. If not, consider changing your password once you are back in.<br/>
+	`, user.FullName, unlockURL)
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send unlock account email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/forabbie/vank-app/database/sqlc"
+	"github.com/forabbie/vank-app/util"
+)
+
+const (
+	TaskSendVerifyEmail   = "task:send_verify_email"
+	verifyEmailSecretSize = 32
+)
+
+type PayloadSendVerifyEmail struct {
+	Username string `json:"username"`
+}
+
+func (distributor *PostgresTaskDistributor) DistributeTaskSendVerifyEmail(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadSendVerifyEmail,
+	opts ...Option,
+) error {
+	return distributor.enqueue(ctx, q, TaskSendVerifyEmail, payload, opts...)
+}
+
+// ProcessTaskSendVerifyEmail creates a verify email record for the user and emails them the verification link
+func (processor *PostgresTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, payload []byte) error {
+	var p PayloadSendVerifyEmail
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", ErrSkipRetry)
+	}
+
+	user, err := processor.store.GetUserByUsername(ctx, p.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user %s doesn't exist: %w", p.Username, ErrSkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secretCode, err := util.RandomSecret(verifyEmailSecretSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate secret code: %w", err)
+	}
+
+	verifyEmail, err := processor.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: secretCode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create verify email: %w", err)
+	}
+
+	verifyURL := fmt.Sprintf("%s/api/v1/verify_email?email_id=%d&secret_code=%s",
+		processor.config.BaseURL, verifyEmail.ID, verifyEmail.SecretCode)
+	subject := "Welcome to Simple Bank"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	Thank you for registering with us!<br/>
+	Please <a href="%s">click here</a> to verify your email address.<br/>
+	`, user.FullName, verifyURL)
+	to := []string{verifyEmail.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send verify email: %w", err)
+	}
+
+	return nil
+}